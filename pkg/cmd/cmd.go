@@ -21,6 +21,10 @@ func NewCmd() *cobra.Command {
 		},
 	}
 	cmd.AddCommand(common.SplitCommand(trigger.NewCmdTrigger()))
+	cmd.AddCommand(common.SplitCommand(trigger.NewCmdRebuild()))
+	cmd.AddCommand(common.SplitCommand(trigger.NewCmdStages()))
+	cmd.AddCommand(common.SplitCommand(trigger.NewCmdLogs()))
+	cmd.AddCommand(common.SplitCommand(trigger.NewCmdArtifacts()))
 	cmd.AddCommand(common.SplitCommand(server.NewCmdAdd()))
 	cmd.AddCommand(common.SplitCommand(server.NewCmdDelete()))
 	cmd.AddCommand(common.SplitCommand(server.NewCmdJobs()))
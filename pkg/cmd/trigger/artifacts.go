@@ -0,0 +1,212 @@
+package trigger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/common"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// DownloadedArtifact an archived artifact of a build which was downloaded
+type DownloadedArtifact struct {
+	// Path the relative path of the artifact in the build
+	Path string `json:"path"`
+
+	// File the local file the artifact was downloaded to
+	File string `json:"file"`
+
+	// Size the size of the artifact in bytes
+	Size int64 `json:"size"`
+}
+
+// ArtifactsOptions contains the command line arguments for this command
+type ArtifactsOptions struct {
+	TriggerOptions
+
+	JobName string
+	Dest    string
+}
+
+var (
+	artifactsLong = templates.LongDesc(`
+		This command downloads the archived artifacts of a pipeline build
+
+		The artifacts are downloaded into the destination directory keeping their relative paths. Use --glob to only
+		download the artifacts whose relative path matches the pattern where '**' matches any number of directories.
+
+		The size of each artifact is verified against the Content-Length returned by Jenkins. Artifacts which Jenkins
+		returns without a Content-Length, such as chunked responses, are not verified.
+
+`)
+
+	artifactsExample = templates.Examples(`
+		# downloads all the artifacts of the last build of a job into the current directory
+		%s artifacts myowner/myrepo/master
+
+		# downloads the jar files of build 42 into the target directory
+		%s artifacts myowner/myrepo/master 42 --dest target --glob '**/*.jar'
+`)
+)
+
+// NewCmdArtifacts creates the new command
+func NewCmdArtifacts() (*cobra.Command, *ArtifactsOptions) {
+	o := &ArtifactsOptions{}
+	cmd := &cobra.Command{
+		Use:     "artifacts <job> [build-number]",
+		Short:   "downloads the archived artifacts of a pipeline build",
+		Long:    artifactsLong,
+		Example: fmt.Sprintf(artifactsExample, common.BinaryName, common.BinaryName),
+		Aliases: []string{"artifact"},
+		Args:    cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			common.SetLoggingLevel(cmd)
+			err := o.parseArgs(args)
+			if err == nil {
+				err = o.Run()
+			}
+			common.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", "", "The jenkins namespace")
+	cmd.Flags().StringVarP(&o.JenkinsSelector.DevelopmentJenkinsURL, "dev-jenkins-url", "", "", "Specifies a local URL to access the jenkins server if you are not running this command inside a Kubernetes cluster and don't have Ingress resosurces for the Jenkins server and so cannot use Kubernetes Service discovery. E.g. could be 'http://localhost:8080' if you are using: kubectl port-forward jenkins-server1 8080:8080")
+	cmd.Flags().StringVarP(&o.Dest, "dest", "d", ".", "The directory to download the artifacts into")
+	cmd.Flags().StringArrayVarP(&o.ArtifactGlobs, "glob", "g", nil, "Only downloads the artifacts whose relative path matches the glob pattern such as '**/*.jar'. Repeat the flag to match multiple patterns")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", fmt.Sprintf("Outputs the downloaded artifacts to stdout in the given format. Supported formats: %s", strings.Join(OutputFormats, ", ")))
+	o.JenkinsSelector.AddFlags(cmd)
+
+	cmd.PersistentFlags().BoolVarP(&o.BatchMode, "batch-mode", "b", os.Getenv("JX_BATCH_MODE") == "true", "Runs in batch mode without prompting for user input")
+	return cmd, o
+}
+
+// parseArgs parses the job name and optional build number arguments
+func (o *ArtifactsOptions) parseArgs(args []string) error {
+	o.JobName = args[0]
+	if len(args) > 1 {
+		number, err := strconv.Atoi(args[1])
+		if err != nil || number <= 0 {
+			return fmt.Errorf("invalid build number %s", args[1])
+		}
+		o.BuildNumber = number
+	}
+	return nil
+}
+
+// Run implements the command
+func (o *ArtifactsOptions) Run() error {
+	err := o.setup()
+	if err != nil {
+		return err
+	}
+	_, jsvc, err := o.PickCustomJenkinsName(&o.JenkinsSelector, true)
+	if err != nil {
+		return err
+	}
+	jenkinsClient, err := jsvc.CreateClient()
+	if err != nil {
+		return err
+	}
+	return o.DownloadBuildArtifacts(jenkinsClient)
+}
+
+// DownloadBuildArtifacts downloads the archived artifacts of the build into the destination directory
+func (o *ArtifactsOptions) DownloadBuildArtifacts(jenkinsClient jenkinsutil.Client) error {
+	job, err := jenkinsutil.GetJobByFullName(jenkinsClient, o.JobName)
+	if err != nil {
+		return err
+	}
+	var build gojenkins.Build
+	if o.BuildNumber > 0 {
+		build, err = jenkinsClient.GetBuild(job, o.BuildNumber)
+	} else {
+		build, err = jenkinsClient.GetLastBuild(job)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot find the build of %s", o.JobName)
+	}
+	if build.Building {
+		log.Logger().Warnf("build %s #%d is still running so only the artifacts archived so far are downloaded", job.FullName, build.Number)
+	}
+
+	err = o.downloadArtifacts(jenkinsClient, job, &build, o.Dest)
+	if err != nil {
+		return err
+	}
+	if o.Output != "" {
+		return o.writeOutput(o.Result.Artifacts)
+	}
+	return nil
+}
+
+// downloadArtifacts downloads the archived artifacts of the build matching --glob into the directory keeping
+// their relative paths
+func (o *TriggerOptions) downloadArtifacts(jenkinsClient jenkinsutil.Client, job gojenkins.Job, build *gojenkins.Build, dir string) error {
+	artifacts, err := jenkinsutil.FilterArtifacts(build.Artifacts, o.ArtifactGlobs)
+	if err != nil {
+		return err
+	}
+	if len(artifacts) == 0 {
+		log.Logger().Infof("build %s #%d has no archived artifacts to download", job.FullName, build.Number)
+		return nil
+	}
+	for _, artifact := range artifacts {
+		downloaded, err := downloadArtifact(jenkinsClient, build, artifact, dir)
+		if err != nil {
+			return err
+		}
+		o.Result.Artifacts = append(o.Result.Artifacts, *downloaded)
+	}
+	log.Logger().Infof("downloaded %d artifacts of %s #%d to %s", len(artifacts), job.FullName, build.Number, util.ColorInfo(dir))
+	return nil
+}
+
+// downloadArtifact downloads the artifact into the directory. Any partially downloaded file is removed
+func downloadArtifact(jenkinsClient jenkinsutil.Client, build *gojenkins.Build, artifact gojenkins.Artifact, dir string) (*DownloadedArtifact, error) {
+	path, err := artifactPath(dir, artifact.RelativePath)
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the directory for artifact %s", path)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create artifact %s", path)
+	}
+	size, err := jenkinsClient.DownloadArtifact(*build, artifact, f)
+	closeErr := f.Close()
+	if err == nil && closeErr != nil {
+		err = errors.Wrapf(closeErr, "failed to close artifact %s", path)
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	log.Logger().Debugf("downloaded artifact %s to %s (%d bytes)", artifact.RelativePath, path, size)
+	return &DownloadedArtifact{
+		Path: artifact.RelativePath,
+		File: path,
+		Size: size,
+	}, nil
+}
+
+// artifactPath returns the local file for the relative path of the artifact ensuring it is inside the directory
+func artifactPath(dir string, relativePath string) (string, error) {
+	path := filepath.Join(dir, filepath.FromSlash(relativePath))
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("artifact %s is outside of the directory %s", relativePath, dir)
+	}
+	return path, nil
+}
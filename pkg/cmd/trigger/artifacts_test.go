@@ -0,0 +1,99 @@
+package trigger_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/cmd/trigger"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil/fake"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/magiconair/properties/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testArtifacts = []gojenkins.Artifact{
+	{FileName: "app.jar", RelativePath: "target/app.jar"},
+	{FileName: "report.xml", RelativePath: "target/reports/report.xml"},
+}
+
+var testArtifactContents = map[string]string{
+	"target/app.jar":            "jar contents",
+	"target/reports/report.xml": "<testsuite/>",
+}
+
+func TestDownloadBuildArtifacts(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test-artifacts-")
+	require.NoError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(tmpDir)
+
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		FolderXMLJobs: []fake.FolderXMLJob{
+			{
+				Folder:  "myowner/job/myrepo",
+				JobName: "master",
+			},
+		},
+		Builds: []gojenkins.Build{
+			{Number: 7, Result: "SUCCESS", Artifacts: testArtifacts},
+		},
+		ArtifactContents: testArtifactContents,
+	}
+
+	_, o := trigger.NewCmdArtifacts()
+	o.JobName = "myowner/myrepo/master"
+	o.BuildNumber = 7
+	o.Dest = filepath.Join(tmpDir, "dest")
+	o.ArtifactGlobs = []string{"**/*.jar"}
+
+	err = o.DownloadBuildArtifacts(jenkinsClient)
+	require.NoError(t, err, "should not have failed")
+
+	require.Equal(t, 1, len(o.Result.Artifacts), "downloaded artifacts")
+	assert.Equal(t, o.Result.Artifacts[0].Path, "target/app.jar", "artifact path")
+	assert.Equal(t, o.Result.Artifacts[0].Size, int64(12), "artifact size")
+
+	data, err := ioutil.ReadFile(filepath.Join(o.Dest, "target", "app.jar"))
+	require.NoError(t, err, "failed to read downloaded artifact")
+	assert.Equal(t, string(data), "jar contents", "artifact contents")
+
+	_, err = os.Stat(filepath.Join(o.Dest, "target", "reports", "report.xml"))
+	assert.Equal(t, os.IsNotExist(err), true, "should not have downloaded the artifacts which do not match the glob")
+}
+
+func TestTriggerDownloadArtifacts(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test-trigger-artifacts-")
+	require.NoError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(tmpDir)
+
+	_, o := trigger.NewCmdTrigger()
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.JenkinsPath = "myowner/myrepo/master"
+	o.Wait = true
+	o.DownloadArtifacts = filepath.Join(tmpDir, "artifacts")
+	gitInfo := &gits.GitRepository{
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue:     "https://jenkins.acme.com",
+		BuildResult:      "SUCCESS",
+		BuildArtifacts:   testArtifacts,
+		ArtifactContents: testArtifactContents,
+	}
+
+	err = o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.NoError(t, err, "should not have failed")
+
+	require.Equal(t, 2, len(o.Result.Artifacts), "downloaded artifacts")
+	for path, contents := range testArtifactContents {
+		data, err := ioutil.ReadFile(filepath.Join(o.DownloadArtifacts, filepath.FromSlash(path)))
+		require.NoError(t, err, "failed to read downloaded artifact %s", path)
+		assert.Equal(t, string(data), contents, "contents of "+path)
+	}
+}
@@ -0,0 +1,145 @@
+package trigger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/helpers"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+// StoppedBuild a build which was stopped along with its final result
+type StoppedBuild struct {
+	// Number the number of the build
+	Number int `json:"number"`
+
+	// URL the URL of the build
+	URL string `json:"url,omitempty"`
+
+	// Result the result of the build once it stopped
+	Result string `json:"result,omitempty"`
+}
+
+// cancelBuilds stops the last build along with any queued builds, the build of --build-number or all running and
+// queued builds if all is true then waits for them to stop
+func (o *TriggerOptions) cancelBuilds(jenkins jenkinsutil.Client, job gojenkins.Job, all bool) error {
+	builds, queueItems, err := o.buildsToCancel(jenkins, job, all)
+	if err != nil {
+		return err
+	}
+	if len(builds) == 0 && len(queueItems) == 0 {
+		log.Logger().Infof("there are no running builds of %s to cancel", util.ColorInfo(job.FullName))
+		return nil
+	}
+	err = o.cancelQueueItems(jenkins, job, queueItems)
+	if err != nil {
+		return err
+	}
+	return o.stopBuilds(jenkins, job, builds, o.CancelTimeout)
+}
+
+// buildsToCancel returns the running builds and queued builds of the job to cancel. If all is true then all running
+// and queued builds are returned otherwise the build of --build-number or the last build if it is running along
+// with the queued builds which would otherwise start once it stops
+func (o *TriggerOptions) buildsToCancel(jenkins jenkinsutil.Client, job gojenkins.Job, all bool) ([]gojenkins.Build, []jenkinsutil.QueueItem, error) {
+	var builds []gojenkins.Build
+	switch {
+	case all:
+		allBuilds, err := jenkins.GetBuilds(job)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, build := range allBuilds {
+			if build.Building {
+				builds = append(builds, build)
+			}
+		}
+		queueItems, err := jenkins.GetJobQueueItems(job)
+		if err != nil {
+			return nil, nil, err
+		}
+		return builds, queueItems, nil
+
+	case o.BuildNumber > 0:
+		build, err := jenkins.GetBuild(job, o.BuildNumber)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !build.Building {
+			log.Logger().Infof("build %s #%d is not running as its result is %s", job.FullName, build.Number, build.Result)
+			return nil, nil, nil
+		}
+		builds = append(builds, build)
+
+	default:
+		build, err := jenkins.GetLastBuild(job)
+		if err != nil {
+			return nil, nil, err
+		}
+		if build.Building {
+			builds = append(builds, build)
+		}
+		queueItems, err := jenkins.GetJobQueueItems(job)
+		if err != nil {
+			return nil, nil, err
+		}
+		return builds, queueItems, nil
+	}
+	return builds, nil, nil
+}
+
+// cancelQueueItems removes the queued builds from the build queue
+func (o *TriggerOptions) cancelQueueItems(jenkins jenkinsutil.Client, job gojenkins.Job, queueItems []jenkinsutil.QueueItem) error {
+	for _, item := range queueItems {
+		log.Logger().Infof("removing queued build %d of %s from the queue", item.ID, util.ColorInfo(job.FullName))
+		err := jenkins.CancelQueueItem(item.ID)
+		if err != nil {
+			return err
+		}
+		o.Result.CancelledQueueItems = append(o.Result.CancelledQueueItems, item.ID)
+	}
+	return nil
+}
+
+// stopBuilds stops the builds then waits for them to complete reporting their final result
+func (o *TriggerOptions) stopBuilds(jenkins jenkinsutil.Client, job gojenkins.Job, builds []gojenkins.Build, waitTime time.Duration) error {
+	if len(builds) == 0 {
+		return nil
+	}
+	remaining := map[int]bool{}
+	for _, build := range builds {
+		log.Logger().Infof("cancelling build %s/%d", job.FullName, build.Number)
+		err := jenkins.StopBuild(job, build.Number)
+		if err != nil {
+			return err
+		}
+		remaining[build.Number] = true
+	}
+	err := helpers.Poll(1*time.Second, waitTime, fmt.Sprintf("the builds of %s to stop", job.FullName),
+		func() (bool, error) {
+			for _, b := range builds {
+				if !remaining[b.Number] {
+					continue
+				}
+				build, err := jenkins.GetBuild(job, b.Number)
+				if err != nil {
+					return false, err
+				}
+				if build.Building {
+					continue
+				}
+				delete(remaining, b.Number)
+				log.Logger().Infof("stopped build %s #%d with result %s", job.FullName, build.Number, util.ColorInfo(build.Result))
+				o.Result.StoppedBuilds = append(o.Result.StoppedBuilds, StoppedBuild{
+					Number: build.Number,
+					URL:    build.Url,
+					Result: build.Result,
+				})
+			}
+			return len(remaining) == 0, nil
+		})
+	return err
+}
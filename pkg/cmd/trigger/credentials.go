@@ -0,0 +1,47 @@
+package trigger
+
+import (
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ensureGitCredentials creates the git credentials in Jenkins from the Kubernetes Secret if they do not exist
+func (o *TriggerOptions) ensureGitCredentials(jenkinsClient jenkinsutil.Client) error {
+	if o.GitCredentialsSecret == "" {
+		return nil
+	}
+	_, err := jenkinsClient.GetCredential(o.GitCredentialsID)
+	if err == nil {
+		log.Logger().Debugf("the Jenkins credentials %s already exist", o.GitCredentialsID)
+		return nil
+	}
+	if !isNotFound(jenkinsClient, err) {
+		return errors.Wrapf(err, "failed to find the Jenkins credentials %s", o.GitCredentialsID)
+	}
+	log.Logger().Debugf("failed to find the Jenkins credentials %s: %s", o.GitCredentialsID, err.Error())
+
+	kubeClient := o.ClientFactory.KubeClient
+	// lets default to the namespace of the current kube context as the client factory namespace is only
+	// set to --namespace so that all namespaces are searched for Jenkins servers by default
+	ns := o.Namespace
+	if ns == "" {
+		ns = o.KubeNamespace
+	}
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(o.GitCredentialsSecret, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to load Secret %s in namespace %s", o.GitCredentialsSecret, ns)
+	}
+	username, password, err := jenkinsutil.GitCredentialsFromSecret(secret)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("creating Jenkins credentials %s from Secret %s", util.ColorInfo(o.GitCredentialsID), util.ColorInfo(o.GitCredentialsSecret))
+	err = jenkinsClient.CreateCredential(o.GitCredentialsID, username, password)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the Jenkins credentials %s", o.GitCredentialsID)
+	}
+	return nil
+}
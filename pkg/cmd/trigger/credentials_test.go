@@ -0,0 +1,75 @@
+package trigger_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/cmd/trigger"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil/fake"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/magiconair/properties/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTriggerCreatesGitCredentialsFromSecret(t *testing.T) {
+	testCases := []struct {
+		name            string
+		credentialError error
+		created         bool
+		fail            bool
+	}{
+		{"missing credentials", nil, true, false},
+		{"cannot get credentials", errors.New("403 Forbidden"), false, true},
+	}
+	for _, tc := range testCases {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "git-secret",
+				Namespace: "jx",
+			},
+			Data: map[string][]byte{
+				"username": []byte("myuser"),
+				"password": []byte("mypassword"),
+			},
+		}
+		jenkinsClient := &fake.FakeClient{
+			BaseURLValue:    "https://jenkins.acme.com",
+			CredentialError: tc.credentialError,
+		}
+		gitInfo := &gits.GitRepository{
+			URL:          "https://github.com/myowner/myrepo.git",
+			Host:         "https://github.com",
+			Organisation: "myowner",
+			Name:         "myrepo",
+		}
+
+		_, o := trigger.NewCmdTrigger()
+		// lets simulate setup without --namespace which clears the client factory namespace
+		o.ClientFactory = &jenkinsutil.ClientFactory{KubeClient: kubefake.NewSimpleClientset(secret)}
+		o.KubeNamespace = "jx"
+		o.Branch = "master"
+		o.Dir = filepath.Join("test_data/sample")
+		o.JenkinsPath = "myowner/myrepo/master"
+		o.GitCredentialsSecret = "git-secret"
+		o.GitCredentialsID = "git-secret"
+
+		err := o.TriggerPipeline(jenkinsClient, gitInfo)
+		if tc.fail {
+			require.Error(t, err, "should have failed for %s", tc.name)
+		} else {
+			require.NoError(t, err, "should not have failed for %s", tc.name)
+		}
+
+		if tc.created {
+			require.Equal(t, 1, len(jenkinsClient.Credentials), "should have created the credentials for %s", tc.name)
+			assert.Equal(t, jenkinsClient.Credentials[0], fake.FakeCredential{ID: "git-secret", Username: "myuser", Password: "mypassword"}, "credentials for "+tc.name)
+		} else {
+			assert.Equal(t, len(jenkinsClient.Credentials), 0, "should not have created the credentials for "+tc.name)
+		}
+	}
+}
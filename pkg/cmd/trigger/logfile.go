@@ -0,0 +1,159 @@
+package trigger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/common"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	// LogStreamFull writes the full build log to the terminal when tailing
+	LogStreamFull = "full"
+
+	// LogStreamCondensed only writes the stages, errors, warnings and result of the build to the terminal when tailing
+	LogStreamCondensed = "condensed"
+)
+
+// LogStreams the supported values of --log-stream
+var LogStreams = []string{LogStreamFull, LogStreamCondensed}
+
+// tailLog writes the tailed build log to the terminal and the log file if there is one. It can be closed while
+// the log is being written so that the log file is flushed and closed if the command is interrupted
+type tailLog struct {
+	lock    sync.Mutex
+	console io.WriteCloser
+	file    *os.File
+	buffer  *bufio.Writer
+	closed  bool
+}
+
+// Write implements io.Writer
+func (l *tailLog) Write(p []byte) (int, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.closed {
+		return 0, os.ErrClosed
+	}
+	if l.buffer != nil {
+		_, err := l.buffer.Write(p)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to write to log file %s", l.file.Name())
+		}
+	}
+	return l.console.Write(p)
+}
+
+// Close writes any incomplete line to the terminal then flushes and closes the log file
+func (l *tailLog) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	err := l.console.Close()
+	if l.file == nil {
+		return err
+	}
+	fileErr := l.buffer.Flush()
+	if fileErr == nil {
+		fileErr = l.file.Sync()
+	}
+	closeErr := l.file.Close()
+	if fileErr == nil {
+		fileErr = closeErr
+	}
+	if fileErr != nil {
+		return errors.Wrapf(fileErr, "failed to close log file %s", l.file.Name())
+	}
+	return err
+}
+
+// tailOutput returns the writer for the tailed build log which writes the full or condensed log to the terminal
+// formatted via the log formatting flags and the unmodified full log to the --log-file if specified. The writer
+// must be closed once the tail completes
+func (o *TriggerOptions) tailOutput(jobName string, buildNumber int) (io.WriteCloser, error) {
+	out := o.tailWriter()
+	l := &tailLog{console: nopWriteCloser{out}}
+	filters := o.logFilters(jobName, buildNumber)
+	if len(filters) > 0 {
+		l.console = jenkinsutil.NewLineWriter(out, jenkinsutil.ChainLineFilters(filters...))
+	}
+	if o.LogFile != "" {
+		err := os.MkdirAll(filepath.Dir(o.LogFile), 0755)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create the directory for log file %s", o.LogFile)
+		}
+		l.file, err = os.Create(o.LogFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create log file %s", o.LogFile)
+		}
+		l.buffer = bufio.NewWriter(l.file)
+	}
+	return l, nil
+}
+
+// logFilters returns the filters for the lines of the build log written to the terminal. Console notes and ANSI
+// codes are removed before condensing the log so that the prefix and timestamp are only added to the lines kept
+func (o *TriggerOptions) logFilters(jobName string, buildNumber int) []jenkinsutil.LineFilter {
+	var filters []jenkinsutil.LineFilter
+	if filter := jenkinsutil.ConsoleNotesFilter(o.ConsoleNotes); filter != nil {
+		filters = append(filters, filter)
+	}
+	if o.StripANSI {
+		filters = append(filters, jenkinsutil.StripANSIFilter)
+	}
+	if o.LogStream == LogStreamCondensed {
+		filters = append(filters, jenkinsutil.CondensedLogFilter)
+	}
+	prefix := ""
+	if o.LogPrefix {
+		prefix = fmt.Sprintf("[%s#%d] ", jobName, buildNumber)
+	}
+	if prefix != "" || o.LogTimestamps {
+		filters = append(filters, jenkinsutil.PrefixFilter(prefix, o.LogTimestamps, time.Now))
+	}
+	return filters
+}
+
+// closeOnInterrupt closes the tailed log then exits if the command is interrupted so that the log file is
+// complete. The returned function stops watching for interrupts
+func (o *TriggerOptions) closeOnInterrupt(l io.Closer, jobName string) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-signals:
+			err := l.Close()
+			if err == nil {
+				err = fmt.Errorf("received %s while tailing the log of %s", sig, jobName)
+			}
+			common.CheckErr(common.NewExitCodeError(common.ExitError, err))
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close implements io.Closer
+func (nopWriteCloser) Close() error {
+	return nil
+}
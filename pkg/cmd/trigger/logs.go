@@ -0,0 +1,156 @@
+package trigger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/common"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// LogsOptions contains the command line arguments for this command
+type LogsOptions struct {
+	TriggerOptions
+
+	JobName string
+	Follow  bool
+}
+
+var (
+	logsLong = templates.LongDesc(`
+		This command displays the build log of a pipeline build
+
+		If no job is specified the job is found from the $REPO_OWNER, $REPO_NAME and git branch or $JOB_NAME
+		environment variables. The last build of the job is used if no build number is specified.
+
+		Use --follow to tail the log of a running build until it completes.
+
+`)
+
+	logsExample = templates.Examples(`
+		# displays the log of the last build of the current git repository and branch
+		%s logs
+
+		# follows the log of the last build of a job
+		%s logs myowner/myrepo/master -f
+
+		# displays the log of build 42 of a job without colours
+		%s logs myowner/myrepo/master 42 --strip-ansi
+`)
+)
+
+// NewCmdLogs creates the new command
+func NewCmdLogs() (*cobra.Command, *LogsOptions) {
+	o := &LogsOptions{}
+	cmd := &cobra.Command{
+		Use:     "logs [job] [build-number]",
+		Short:   "displays the build log of a pipeline build",
+		Long:    logsLong,
+		Example: fmt.Sprintf(logsExample, common.BinaryName, common.BinaryName, common.BinaryName),
+		Aliases: []string{"log"},
+		Args:    cobra.MaximumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			common.SetLoggingLevel(cmd)
+			err := o.parseArgs(args)
+			if err == nil {
+				err = o.Run()
+			}
+			common.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", "", "The jenkins namespace")
+	cmd.Flags().StringVarP(&o.JenkinsSelector.DevelopmentJenkinsURL, "dev-jenkins-url", "", "", "Specifies a local URL to access the jenkins server if you are not running this command inside a Kubernetes cluster and don't have Ingress resosurces for the Jenkins server and so cannot use Kubernetes Service discovery. E.g. could be 'http://localhost:8080' if you are using: kubectl port-forward jenkins-server1 8080:8080")
+	cmd.Flags().BoolVarP(&o.Follow, "follow", "f", false, "Follows the build log until the build completes if the build is running")
+	cmd.Flags().DurationVarP(&o.BuildTimeout, "build-timeout", "", common.DurationFromEnv(BuildTimeoutEnv, 100*time.Hour), fmt.Sprintf("How long to follow the build log of a running build. Defaults to $%s if set", BuildTimeoutEnv))
+	o.addLogFormatFlags(cmd)
+	o.JenkinsSelector.AddFlags(cmd)
+
+	cmd.PersistentFlags().BoolVarP(&o.BatchMode, "batch-mode", "b", os.Getenv("JX_BATCH_MODE") == "true", "Runs in batch mode without prompting for user input")
+	return cmd, o
+}
+
+// parseArgs parses the optional job name and build number arguments
+func (o *LogsOptions) parseArgs(args []string) error {
+	if len(args) > 0 {
+		o.JobName = args[0]
+	}
+	if len(args) > 1 {
+		number, err := strconv.Atoi(args[1])
+		if err != nil || number <= 0 {
+			return fmt.Errorf("invalid build number %s", args[1])
+		}
+		o.BuildNumber = number
+	}
+	return nil
+}
+
+// Run implements the command
+func (o *LogsOptions) Run() error {
+	err := o.validateLogFormat()
+	if err != nil {
+		return err
+	}
+	err = o.setup()
+	if err != nil {
+		return err
+	}
+	_, jsvc, err := o.PickCustomJenkinsName(&o.JenkinsSelector, true)
+	if err != nil {
+		return err
+	}
+	jenkinsClient, err := jsvc.CreateClient()
+	if err != nil {
+		return err
+	}
+	return o.ShowLogs(jenkinsClient)
+}
+
+// ShowLogs writes the console output of the build or tails the build log until the build completes if the build
+// is running and --follow is enabled
+func (o *LogsOptions) ShowLogs(jenkinsClient jenkinsutil.Client) error {
+	if o.JobName == "" {
+		o.JobName = o.GetJenkinsJobName()
+		if o.JobName == "" {
+			return fmt.Errorf("no job specified and could not find one from $REPO_OWNER, $REPO_NAME and the git branch or $JOB_NAME")
+		}
+	}
+	job, err := jenkinsutil.GetJobByFullName(jenkinsClient, o.JobName)
+	if err != nil {
+		return err
+	}
+	var build gojenkins.Build
+	if o.BuildNumber > 0 {
+		build, err = jenkinsClient.GetBuild(job, o.BuildNumber)
+	} else {
+		build, err = jenkinsClient.GetLastBuild(job)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot find the build of %s", o.JobName)
+	}
+
+	if o.Follow && build.Building {
+		return o.tailBuild(jenkinsClient, job, &build)
+	}
+
+	data, err := jenkinsClient.GetBuildConsoleOutput(build)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the build log of %s #%d", job.FullName, build.Number)
+	}
+	out, err := o.tailOutput(job.FullName, build.Number)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	closeErr := out.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package trigger_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/cmd/trigger"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil/fake"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/magiconair/properties/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowLogs(t *testing.T) {
+	testCases := []struct {
+		name        string
+		jobEnv      string
+		jobName     string
+		buildNumber int
+		follow      bool
+		logPrefix   bool
+		expected    string
+	}{
+		{
+			name:        "finished build",
+			jobName:     "myowner/myrepo/master",
+			buildNumber: 7,
+			logPrefix:   true,
+			expected:    "[/job/myowner/job/myrepo/job/master#7] + make build\n[/job/myowner/job/myrepo/job/master#7] Finished: SUCCESS\n",
+		},
+		{
+			name:     "follow last build of job from environment",
+			jobEnv:   "myowner/myrepo/master",
+			follow:   true,
+			expected: "+ make build\nFinished: SUCCESS\n",
+		},
+	}
+	for _, tc := range testCases {
+		tmpDir, err := ioutil.TempDir("", "test-logs-output-")
+		require.NoError(t, err, "failed to create temp dir")
+		defer os.RemoveAll(tmpDir)
+		out, err := os.Create(filepath.Join(tmpDir, "out.txt"))
+		require.NoError(t, err, "failed to create output file")
+		defer out.Close()
+
+		jenkinsClient := &fake.FakeClient{
+			BaseURLValue: "https://jenkins.acme.com",
+			FolderXMLJobs: []fake.FolderXMLJob{
+				{
+					Folder:  "myowner/job/myrepo",
+					JobName: "master",
+				},
+			},
+			Builds: []gojenkins.Build{
+				{Number: 7, Result: "SUCCESS"},
+			},
+			BuildResult: "SUCCESS",
+			ConsoleLog:  "+ make build\nFinished: SUCCESS",
+		}
+
+		_, o := trigger.NewCmdLogs()
+		o.IOFileHandles = &util.IOFileHandles{Out: out, Err: os.Stderr, In: os.Stdin}
+		o.JobName = tc.jobName
+		o.BuildNumber = tc.buildNumber
+		o.Follow = tc.follow
+		o.LogPrefix = tc.logPrefix
+		if tc.jobEnv != "" {
+			os.Setenv("JOB_NAME", tc.jobEnv)
+			os.Setenv("BRANCH_NAME", "master")
+			os.Unsetenv("REPO_OWNER")
+			defer os.Unsetenv("JOB_NAME")
+			defer os.Unsetenv("BRANCH_NAME")
+		}
+
+		err = o.ShowLogs(jenkinsClient)
+		require.NoError(t, err, "should not have failed for %s", tc.name)
+
+		data, err := ioutil.ReadFile(out.Name())
+		require.NoError(t, err, "failed to read output for %s", tc.name)
+		assert.Equal(t, o.JobName, "myowner/myrepo/master", "job name for "+tc.name)
+		assert.Equal(t, string(data), tc.expected, "log for "+tc.name)
+	}
+}
@@ -0,0 +1,390 @@
+package trigger
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/common"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/v2/pkg/table"
+	"github.com/pkg/errors"
+)
+
+// Manifest a list of pipelines to trigger together
+type Manifest struct {
+	// Triggers the pipelines to trigger
+	Triggers []ManifestTrigger `json:"triggers"`
+}
+
+// ManifestTrigger a pipeline to trigger from a manifest which is either an existing Jenkins job
+// or a git repository to create the pipeline for
+type ManifestTrigger struct {
+	// Name the name displayed in the results. Defaults to the job or git URL
+	Name string `json:"name,omitempty"`
+
+	// Server the name of the Jenkins server. Defaults to the --jenkins server
+	Server string `json:"server,omitempty"`
+
+	// Job the full name of an existing Jenkins job such as 'myfolder/myjob'
+	Job string `json:"job,omitempty"`
+
+	// GitURL the git repository to create the pipeline for if no job is specified
+	GitURL string `json:"gitURL,omitempty"`
+
+	// Branch the branch of the git repository. Defaults to master
+	Branch string `json:"branch,omitempty"`
+
+	// Jenkinsfile the name of the Jenkinsfile in the git repository
+	Jenkinsfile string `json:"jenkinsfile,omitempty"`
+
+	// JenkinsPath the folder path of the pipeline. Defaults to 'owner/repoName/branch'
+	JenkinsPath string `json:"jenkinsPath,omitempty"`
+
+	// MultiBranchProject whether to use a multi branch project for the git repository
+	MultiBranchProject bool `json:"multiBranchProject,omitempty"`
+
+	// OrganizationFolder whether to use the multi branch project in the organization folder of the git owner
+	OrganizationFolder bool `json:"organizationFolder,omitempty"`
+
+	// PullRequest the number of the pull request whose PR-N job to trigger in the multi branch project.
+	// Defaults to $PULL_NUMBER when using a multi branch project or organization folder
+	PullRequest int `json:"pullRequest,omitempty"`
+
+	// Params the build parameters. Any ${NAME} expressions in the values are replaced with the environment variable
+	Params jenkinsutil.BuildParameters `json:"params,omitempty"`
+
+	// ParamsFile a YAML, JSON or Java properties file containing build parameters which are overridden by Params.
+	// A relative path is relative to the directory of the manifest
+	ParamsFile string `json:"paramsFile,omitempty"`
+
+	// JobTemplate the Go template file used to create the pipeline XML relative to the directory of the manifest.
+	// Defaults to --job-template
+	JobTemplate string `json:"jobTemplate,omitempty"`
+
+	// FolderTemplate the Go template file used to create the folder XML relative to the directory of the manifest.
+	// Defaults to --folder-template
+	FolderTemplate string `json:"folderTemplate,omitempty"`
+}
+
+// ManifestResult the result of triggering a pipeline from a manifest
+type ManifestResult struct {
+	TriggerResult
+
+	// Name the name of the trigger in the manifest
+	Name string `json:"name"`
+
+	// ExitCode the exit code for the build
+	ExitCode int `json:"exitCode"`
+
+	// Error the reason the pipeline could not be triggered or did not succeed
+	Error string `json:"error,omitempty"`
+}
+
+// LoadManifest loads and validates the manifest file
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load manifest %s", path)
+	}
+	manifest := &Manifest{}
+	err = yaml.Unmarshal(data, manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse manifest %s", path)
+	}
+	if len(manifest.Triggers) == 0 {
+		return nil, fmt.Errorf("the manifest %s has no triggers", path)
+	}
+	for i := range manifest.Triggers {
+		t := &manifest.Triggers[i]
+		if (t.Job == "") == (t.GitURL == "") {
+			return nil, fmt.Errorf("trigger %d in manifest %s should specify either a job or a gitURL", i+1, path)
+		}
+		if t.Name == "" {
+			t.Name = t.Job
+			if t.Name == "" {
+				t.Name = t.GitURL
+				if t.Branch != "" {
+					t.Name += "#" + t.Branch
+				}
+			}
+		}
+	}
+	return manifest, nil
+}
+
+// runManifest triggers all the pipelines in the manifest file
+func (o *TriggerOptions) runManifest() error {
+	if o.Tail {
+		return fmt.Errorf("--tail and --log-file cannot be used with --manifest")
+	}
+	if o.DownloadArtifacts != "" {
+		return fmt.Errorf("--download-artifacts cannot be used with --manifest")
+	}
+	if o.PullRequest != 0 {
+		return fmt.Errorf("--pr cannot be used with --manifest, specify the pullRequest of the triggers in the manifest instead")
+	}
+	manifest, err := LoadManifest(o.Manifest)
+	if err != nil {
+		return err
+	}
+
+	// lets resolve the servers up front as picking a server may prompt the user
+	clients := map[string]jenkinsutil.Client{}
+	serverNames := map[string]string{}
+	for i := range manifest.Triggers {
+		t := &manifest.Triggers[i]
+		name, ok := serverNames[t.Server]
+		if !ok {
+			selector := o.JenkinsSelector
+			if t.Server != "" {
+				selector.JenkinsName = t.Server
+			}
+			var jsvc *jenkinsutil.JenkinsServer
+			name, jsvc, err = o.PickCustomJenkinsName(&selector, true)
+			if err != nil {
+				return err
+			}
+			serverNames[t.Server] = name
+			if clients[name] == nil {
+				clients[name], err = jsvc.CreateClient()
+				if err != nil {
+					return err
+				}
+			}
+		}
+		t.Server = name
+	}
+	return o.TriggerManifest(manifest, clients)
+}
+
+// TriggerManifest triggers the pipelines in the manifest using the clients for each server name then
+// waits for them to complete. At most Parallel pipelines are triggered at the same time
+func (o *TriggerOptions) TriggerManifest(manifest *Manifest, clients map[string]jenkinsutil.Client) error {
+	parallel := o.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	// lets create any git credentials up front rather than racing to create them
+	for _, jenkinsClient := range clients {
+		err := o.ensureGitCredentials(jenkinsClient)
+		if err != nil {
+			return err
+		}
+	}
+
+	// lets create any folders and projects one trigger at a time rather than racing to create them
+	locks := &folderLocks{}
+
+	results := make([]ManifestResult, len(manifest.Triggers))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i := range manifest.Triggers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			t := o.resolveManifestPaths(manifest.Triggers[i])
+			to := o.manifestTriggerOptions(t)
+			to.folderLocks = locks
+			err := to.triggerManifestEntry(clients[t.Server], t)
+			if err != nil {
+				log.Logger().Warnf("%s: %s", t.Name, err.Error())
+			}
+			results[i] = ManifestResult{
+				TriggerResult: to.Result,
+				Name:          t.Name,
+				ExitCode:      common.ExitCode(err),
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	err := o.writeManifestResults(results)
+	if err != nil {
+		return err
+	}
+
+	exitCode := common.ExitSuccess
+	failed := 0
+	for _, r := range results {
+		if r.ExitCode != common.ExitSuccess {
+			failed++
+		}
+		if r.ExitCode > exitCode {
+			exitCode = r.ExitCode
+		}
+	}
+	if failed > 0 {
+		return common.NewExitCodeError(exitCode, fmt.Errorf("%d of %d pipelines did not succeed", failed, len(results)))
+	}
+	return nil
+}
+
+// folderLocks a lock for each top level folder so that concurrent triggers do not both find that a folder or
+// project does not exist and then race to create it
+type folderLocks struct {
+	mutex   sync.Mutex
+	folders map[string]*sync.Mutex
+}
+
+// lock locks the folder returning the function to unlock it. A nil folderLocks does nothing as only
+// manifest triggers run concurrently
+func (l *folderLocks) lock(folder string) func() {
+	if l == nil {
+		return func() {}
+	}
+	l.mutex.Lock()
+	if l.folders == nil {
+		l.folders = map[string]*sync.Mutex{}
+	}
+	m := l.folders[folder]
+	if m == nil {
+		m = &sync.Mutex{}
+		l.folders[folder] = m
+	}
+	l.mutex.Unlock()
+
+	m.Lock()
+	return m.Unlock
+}
+
+// manifestTriggerOptions returns the options to trigger the given manifest trigger. The builds are
+// always waited for rather than tailed so that the logs of concurrent builds are not interleaved
+func (o *TriggerOptions) manifestTriggerOptions(t ManifestTrigger) *TriggerOptions {
+	answer := &TriggerOptions{
+		JenkinsOptions:     o.JenkinsOptions,
+		MultiBranchProject: t.MultiBranchProject,
+		OrganizationFolder: t.OrganizationFolder,
+		PullRequest:        t.PullRequest,
+		Jenkinsfile:        t.Jenkinsfile,
+		JenkinsPath:        t.JenkinsPath,
+		Branch:             t.Branch,
+		Wait:               true,
+		UnstableOK:         o.UnstableOK,
+		PollInterval:       o.PollInterval,
+		ProgressInterval:   o.ProgressInterval,
+		StartTimeout:       o.StartTimeout,
+		BuildTimeout:       o.BuildTimeout,
+		ScanTimeout:        o.ScanTimeout,
+		CancelTimeout:      o.CancelTimeout,
+		AbortOnTimeout:     o.AbortOnTimeout,
+		Supersede:          o.Supersede,
+		Reconcile:          o.Reconcile,
+		JobTemplate:        o.JobTemplate,
+		FolderTemplate:     o.FolderTemplate,
+		GitCredentialsID:   o.GitCredentialsID,
+	}
+	answer.Result.Server = t.Server
+	if answer.Branch == "" {
+		answer.Branch = "master"
+	}
+	if answer.Jenkinsfile == "" {
+		answer.Jenkinsfile = jenkinsfile.Name
+	}
+	if t.JobTemplate != "" {
+		answer.JobTemplate = t.JobTemplate
+	}
+	if t.FolderTemplate != "" {
+		answer.FolderTemplate = t.FolderTemplate
+	}
+	return answer
+}
+
+// resolveManifestPaths returns the manifest trigger with any relative file paths resolved against the
+// directory of the manifest file so that the manifest can be used from any directory
+func (o *TriggerOptions) resolveManifestPaths(t ManifestTrigger) ManifestTrigger {
+	dir := filepath.Dir(o.Manifest)
+	for _, path := range []*string{&t.ParamsFile, &t.JobTemplate, &t.FolderTemplate} {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(dir, *path)
+		}
+	}
+	return t
+}
+
+// triggerManifestEntry finds or creates the job for the manifest trigger then triggers it and waits for the result
+func (o *TriggerOptions) triggerManifestEntry(jenkinsClient jenkinsutil.Client, t ManifestTrigger) error {
+	if jenkinsClient == nil {
+		return fmt.Errorf("no Jenkins client for server %s", t.Server)
+	}
+	params := jenkinsutil.ExpandBuildParameters(url.Values(t.Params))
+	if t.ParamsFile != "" {
+		fileParams, err := jenkinsutil.LoadBuildParametersFile(t.ParamsFile)
+		if err != nil {
+			return err
+		}
+		params = jenkinsutil.MergeBuildParameters(fileParams, params)
+	}
+
+	var job gojenkins.Job
+	var err error
+	if t.Job != "" {
+		job, err = jenkinsutil.GetJobByFullName(jenkinsClient, t.Job)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := o.resolvePullRequest()
+		if err != nil {
+			return err
+		}
+		gitInfo, err := gits.ParseGitURL(t.GitURL)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse git URL %s", t.GitURL)
+		}
+		if o.JenkinsPath == "" {
+			o.JenkinsPath = fmt.Sprintf("%s/%s/%s", gitInfo.Organisation, gitInfo.Name, o.Branch)
+		}
+		job, err = o.getOrCreatePipelineFactory(jenkinsClient, gitInfo)()
+		if err != nil {
+			return errors.Wrapf(err, "cannot create pipeline for %s", t.GitURL)
+		}
+	}
+	return o.triggerJob(jenkinsClient, job, params)
+}
+
+// writeManifestResults writes the results in the output format or as a table if no format is specified
+func (o *TriggerOptions) writeManifestResults(results []ManifestResult) error {
+	out := o.GetIOFileHandles().Out
+	if o.Output != "" {
+		return o.writeOutput(results)
+	}
+
+	t := table.CreateTable(out)
+	t.AddRow("NAME", "SERVER", "JOB", "BUILD", "RESULT", "DURATION", "URL")
+	for _, r := range results {
+		build := ""
+		if r.BuildNumber > 0 {
+			build = strconv.Itoa(r.BuildNumber)
+		}
+		result := r.Result
+		if result == "" && r.Error != "" {
+			result = "ERROR"
+			if r.ExitCode == common.ExitTimeout {
+				result = "TIMEOUT"
+			}
+		}
+		duration := ""
+		if r.DurationMillis > 0 {
+			duration = (time.Duration(r.DurationMillis) * time.Millisecond).Round(time.Second).String()
+		}
+		t.AddRow(r.Name, r.Server, r.Job, build, result, duration, r.BuildURL)
+	}
+	t.Render()
+	return nil
+}
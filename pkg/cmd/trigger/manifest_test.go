@@ -0,0 +1,213 @@
+package trigger_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/cmd/trigger"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/common"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil/fake"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/magiconair/properties/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadManifest(t *testing.T) {
+	manifest, err := trigger.LoadManifest(filepath.Join("test_data", "manifest", "triggers.yaml"))
+	require.NoError(t, err, "failed to load manifest")
+	require.Equal(t, 2, len(manifest.Triggers), "triggers")
+
+	assert.Equal(t, manifest.Triggers[0].Name, "release", "name")
+	assert.Equal(t, manifest.Triggers[0].Params["VERSION"], []string{"1.2.3"}, "VERSION parameter")
+	assert.Equal(t, manifest.Triggers[0].Params["TARGETS"], []string{"linux", "darwin"}, "TARGETS parameter")
+	assert.Equal(t, manifest.Triggers[1].Name, "https://github.com/myowner/myrepo.git#master", "default name")
+
+	_, err = trigger.LoadManifest(filepath.Join("test_data", "manifest", "invalid.yaml"))
+	require.Error(t, err, "should fail if a trigger has both a job and a gitURL")
+}
+
+func TestTriggerManifest(t *testing.T) {
+	testCases := []struct {
+		result   string
+		exitCode int
+	}{
+		{"SUCCESS", common.ExitSuccess},
+		{"UNSTABLE", common.ExitUnstable},
+	}
+	for _, tc := range testCases {
+		_, o := trigger.NewCmdTrigger()
+
+		tmpDir, err := ioutil.TempDir("", "test-trigger-manifest-")
+		require.NoError(t, err, "failed to create temp dir")
+		defer os.RemoveAll(tmpDir)
+		outFile := filepath.Join(tmpDir, "out.json")
+		out, err := os.Create(outFile)
+		require.NoError(t, err, "failed to create output file")
+		defer out.Close()
+		o.IOFileHandles = &util.IOFileHandles{Out: out, Err: os.Stderr, In: os.Stdin}
+		o.Output = "json"
+
+		manifest, err := trigger.LoadManifest(filepath.Join("test_data", "manifest", "triggers.yaml"))
+		require.NoError(t, err, "failed to load manifest")
+
+		jenkinsClient := &fake.FakeClient{
+			BaseURLValue: "https://jenkins.acme.com",
+			BuildResult:  tc.result,
+			Jobs: []gojenkins.Job{
+				{Name: "myjob", FullName: "myjob", Url: "https://jenkins.acme.com/job/myjob"},
+			},
+		}
+		err = o.TriggerManifest(manifest, map[string]jenkinsutil.Client{"": jenkinsClient})
+		assert.Equal(t, common.ExitCode(err), tc.exitCode, "exit code for result %s", tc.result)
+		require.Equal(t, 2, len(jenkinsClient.BuildRequests), "build requests")
+
+		data, err := ioutil.ReadFile(outFile)
+		require.NoError(t, err, "failed to read output file")
+		var results []trigger.ManifestResult
+		require.NoError(t, json.Unmarshal(data, &results), "failed to parse results %s", string(data))
+		require.Equal(t, 2, len(results), "results")
+		assert.Equal(t, results[0].Name, "release", "name")
+		assert.Equal(t, results[0].Job, "myjob", "job")
+		for _, r := range results {
+			assert.Equal(t, r.Result, tc.result, "result of %s", r.Name)
+			assert.Equal(t, r.ExitCode, tc.exitCode, "exit code of %s", r.Name)
+		}
+	}
+}
+
+func TestTriggerManifestPullRequest(t *testing.T) {
+	err := os.Setenv(trigger.PullNumberEnv, "123")
+	require.NoError(t, err, "failed to set environment variable")
+	defer os.Unsetenv(trigger.PullNumberEnv)
+
+	projectXML, err := jenkinsutil.CreateMultiBranchProjectXML("https://github.com/myowner/myrepo.git", "", "Jenkinsfile")
+	require.NoError(t, err, "failed to create multibranch project XML")
+
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		BuildResult:  "SUCCESS",
+		FolderXMLJobs: []fake.FolderXMLJob{
+			{
+				JobItemXml: projectXML,
+				Folder:     "myowner",
+				JobName:    "myrepo",
+			},
+		},
+		ScanBranches: []string{"master", "PR-123"},
+	}
+	manifest := &trigger.Manifest{
+		Triggers: []trigger.ManifestTrigger{
+			{
+				Name:               "myrepo",
+				GitURL:             "https://github.com/myowner/myrepo.git",
+				MultiBranchProject: true,
+			},
+		},
+	}
+
+	tmpDir, err := ioutil.TempDir("", "test-trigger-manifest-pr-")
+	require.NoError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(tmpDir)
+	out, err := os.Create(filepath.Join(tmpDir, "out.txt"))
+	require.NoError(t, err, "failed to create output file")
+	defer out.Close()
+
+	_, o := trigger.NewCmdTrigger()
+	o.IOFileHandles = &util.IOFileHandles{Out: out, Err: os.Stderr, In: os.Stdin}
+	err = o.TriggerManifest(manifest, map[string]jenkinsutil.Client{"": jenkinsClient})
+	require.NoError(t, err, "should not have failed")
+
+	require.Equal(t, 2, len(jenkinsClient.BuildRequests), "should have scanned the project then triggered the pull request")
+	assert.Equal(t, jenkinsClient.BuildRequests[1].Job.Url, "https://jenkins.acme.com/job/myowner/job/myrepo/job/PR-123", "triggered pull request job")
+}
+
+func TestTriggerManifestParams(t *testing.T) {
+	err := os.Setenv("TP_TEST_REGISTRY", "gcr.io/myproject")
+	require.NoError(t, err, "failed to set environment variable")
+	defer os.Unsetenv("TP_TEST_REGISTRY")
+
+	tmpDir, err := ioutil.TempDir("", "test-trigger-manifest-params-")
+	require.NoError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(tmpDir)
+	out, err := os.Create(filepath.Join(tmpDir, "out.txt"))
+	require.NoError(t, err, "failed to create output file")
+	defer out.Close()
+
+	_, o := trigger.NewCmdTrigger()
+	o.IOFileHandles = &util.IOFileHandles{Out: out, Err: os.Stderr, In: os.Stdin}
+	o.Manifest = filepath.Join("test_data", "manifest", "params.yaml")
+
+	manifest, err := trigger.LoadManifest(o.Manifest)
+	require.NoError(t, err, "failed to load manifest")
+
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		BuildResult:  "SUCCESS",
+		Jobs: []gojenkins.Job{
+			{Name: "myjob", FullName: "myjob", Url: "https://jenkins.acme.com/job/myjob"},
+		},
+	}
+	err = o.TriggerManifest(manifest, map[string]jenkinsutil.Client{"": jenkinsClient})
+	require.NoError(t, err, "should load the params file relative to the manifest")
+
+	require.Equal(t, 1, len(jenkinsClient.BuildRequests), "build requests")
+	params := jenkinsClient.BuildRequests[0].Values
+	assert.Equal(t, params.Get("VERSION"), "1.2.3", "VERSION from the params file")
+	assert.Equal(t, params.Get("REGISTRY"), "gcr.io/myproject", "REGISTRY from the params file")
+	assert.Equal(t, params.Get("IMAGE"), "gcr.io/myproject/app", "IMAGE from the manifest")
+	assert.Equal(t, params.Get("PASSWORD"), "pa$$word", "PASSWORD from the manifest")
+	assert.Equal(t, manifest.Triggers[0].Params["IMAGE"], []string{"${TP_TEST_REGISTRY}/app"}, "manifest should not be modified")
+}
+
+// slowLookupClient delays returning the result of looking up a top level folder so that concurrent triggers
+// would both find that the owner folder does not exist and race to create it
+type slowLookupClient struct {
+	*fake.FakeClient
+}
+
+func (c *slowLookupClient) GetJobByPath(paths ...string) (gojenkins.Job, error) {
+	job, err := c.FakeClient.GetJobByPath(paths...)
+	if len(paths) == 1 {
+		time.Sleep(100 * time.Millisecond)
+	}
+	return job, err
+}
+
+func TestTriggerManifestSharedOwnerFolder(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test-trigger-manifest-owner-")
+	require.NoError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(tmpDir)
+	out, err := os.Create(filepath.Join(tmpDir, "out.txt"))
+	require.NoError(t, err, "failed to create output file")
+	defer out.Close()
+
+	manifest := &trigger.Manifest{
+		Triggers: []trigger.ManifestTrigger{
+			{Name: "repo1", GitURL: "https://github.com/myowner/repo1.git"},
+			{Name: "repo2", GitURL: "https://github.com/myowner/repo2.git"},
+			{Name: "repo3", GitURL: "https://github.com/myowner/repo3.git", MultiBranchProject: true},
+			{Name: "repo4", GitURL: "https://github.com/myowner/repo4.git", MultiBranchProject: true},
+		},
+	}
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		BuildResult:  "SUCCESS",
+		ScanBranches: []string{"master"},
+	}
+
+	_, o := trigger.NewCmdTrigger()
+	o.IOFileHandles = &util.IOFileHandles{Out: out, Err: os.Stderr, In: os.Stdin}
+	o.Parallel = len(manifest.Triggers)
+	err = o.TriggerManifest(manifest, map[string]jenkinsutil.Client{"": &slowLookupClient{jenkinsClient}})
+	require.NoError(t, err, "should not have failed")
+
+	require.Equal(t, 1, len(jenkinsClient.XMLJobs), "should have created the owner folder once")
+	assert.Equal(t, jenkinsClient.XMLJobs[0].JobName, "myowner", "owner folder")
+	assert.Equal(t, len(jenkinsClient.BuildRequests), 6, "should have scanned the multi branch projects and triggered all the pipelines")
+}
@@ -0,0 +1,202 @@
+package trigger
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/helpers"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/jenkins"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// PullNumberEnv the environment variable containing the pull request number which is set by Lighthouse and Prow
+const PullNumberEnv = "PULL_NUMBER"
+
+// scanLogTailLines the number of lines of the branch indexing log to include in the error if the branch is not found
+const scanLogTailLines = 20
+
+// pullRequestDiscoveryHint explains why a scan may not create a PR-N job. The multi branch projects created by tp
+// use a plain git branch source which cannot discover pull requests
+const pullRequestDiscoveryHint = " Pull request jobs are only created by branch sources which discover pull requests, such as GitHub Branch Source." +
+	" Multi branch projects created by tp only discover branches so configure pull request discovery on the project or use --organization-folder."
+
+// resolvePullRequest defaults the pull request number from $PULL_NUMBER when using a multi branch project or
+// organization folder. Pull request jobs only exist in multi branch projects so --pr implies --multi-branch-project
+func (o *TriggerOptions) resolvePullRequest() error {
+	if o.PullRequest == 0 && (o.MultiBranchProject || o.OrganizationFolder) {
+		value := os.Getenv(PullNumberEnv)
+		if value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil || number <= 0 {
+				return fmt.Errorf("invalid pull request number %s in $%s", value, PullNumberEnv)
+			}
+			o.PullRequest = number
+		}
+	}
+	if o.PullRequest < 0 {
+		return fmt.Errorf("invalid pull request number %d", o.PullRequest)
+	}
+	if o.PullRequest > 0 && !o.OrganizationFolder {
+		o.MultiBranchProject = true
+	}
+	return nil
+}
+
+// branchJobName returns the name of the job for the pull request or branch inside the multi branch project
+func (o *TriggerOptions) branchJobName() string {
+	if o.PullRequest > 0 {
+		return fmt.Sprintf("PR-%d", o.PullRequest)
+	}
+	return o.Branch
+}
+
+// getOrganizationFolderPipeline returns the branch job of the repository in the organization folder of the git owner.
+// If the organization folder does not contain the repository yet the organization folder is scanned
+func (o *TriggerOptions) getOrganizationFolderPipeline(jenkinsClient jenkinsutil.Client, gitInfo *gits.GitRepository) (gojenkins.Job, error) {
+	owner := gitInfo.Organisation
+	name := gitInfo.Name
+	job, err := jenkinsClient.GetMultiBranchJob(owner, name, o.branchJobName())
+	if err == nil {
+		return job, nil
+	}
+	folder, err := jenkinsClient.GetJobByPath(owner)
+	if err != nil {
+		return folder, errors.Wrapf(err, "cannot find the organization folder %s", owner)
+	}
+	if folder.Class != "" && folder.Class != jenkinsutil.OrganizationFolderClass {
+		log.Logger().Warnf("Warning the organization folder %s is of class %s", owner, folder.Class)
+	}
+	folder.Url = jenkins.SwitchJenkinsBaseURL(folder.Url, jenkinsClient.BaseURL())
+
+	project, err := jenkinsClient.GetJobByPath(owner, name)
+	if err != nil {
+		if !isNotFound(jenkinsClient, err) {
+			return project, errors.Wrapf(err, "failed to find multibranch project %s/%s", owner, name)
+		}
+		project, err = o.scanOrganizationFolder(jenkinsClient, folder, gitInfo)
+		if err != nil {
+			return project, err
+		}
+
+		// the organization scan indexes the branches of the repositories it discovers
+		job, err = jenkinsClient.GetMultiBranchJob(owner, name, o.branchJobName())
+		if err == nil {
+			return job, nil
+		}
+	}
+	project.Url = jenkins.SwitchJenkinsBaseURL(project.Url, jenkinsClient.BaseURL())
+	return o.scanMultiBranchProject(jenkinsClient, project, gitInfo)
+}
+
+// scanOrganizationFolder scans the organization folder then waits for the scan result returning the multi branch
+// project of the repository which the scan discovered
+func (o *TriggerOptions) scanOrganizationFolder(jenkinsClient jenkinsutil.Client, folder gojenkins.Job, gitInfo *gits.GitRepository) (gojenkins.Job, error) {
+	log.Logger().Infof("scanning organization folder %s for repository %s", util.ColorInfo(folder.FullName), util.ColorInfo(gitInfo.Name))
+	err := jenkinsClient.Build(folder, url.Values{})
+	if err != nil {
+		return folder, errors.Wrapf(err, "failed to scan organization folder %s", folder.FullName)
+	}
+
+	// lets check the scan result about once a second until the scan timeout
+	retries := int(o.ScanTimeout / time.Second)
+	if retries < 1 {
+		retries = 1
+	}
+	result, err := jenkinsClient.GetOrganizationScanResult(retries, folder)
+	if err != nil {
+		return folder, errors.Wrapf(err, "failed to get the scan result of organization folder %s", folder.FullName)
+	}
+	if result != "SUCCESS" {
+		return folder, fmt.Errorf("the scan of organization folder %s completed with result %s", folder.FullName, util.ColorInfo(result))
+	}
+
+	project, err := jenkinsClient.GetJobByPath(gitInfo.Organisation, gitInfo.Name)
+	if err != nil {
+		return project, errors.Wrapf(err, "the scan of organization folder %s did not discover the repository %s", folder.FullName, gitInfo.Name)
+	}
+	return project, nil
+}
+
+// scanMultiBranchProject scans the multi branch project then follows the branch indexing until the branch job is
+// created. If the indexing completes without creating the branch job an error is returned with the end of its log
+func (o *TriggerOptions) scanMultiBranchProject(jenkinsClient jenkinsutil.Client, project gojenkins.Job, gitInfo *gits.GitRepository) (gojenkins.Job, error) {
+	var previousStart int64
+	previous, err := jenkinsClient.GetIndexing(project)
+	if err != nil {
+		if !isNotFound(jenkinsClient, err) {
+			return project, err
+		}
+	} else {
+		previousStart = previous.Timestamp
+	}
+
+	log.Logger().Infof("scanning multibranch project %s", project.FullName)
+	err = jenkinsClient.Build(project, url.Values{})
+	if err != nil {
+		return project, errors.Wrapf(err, "failed to scan multibranch project %s", project.FullName)
+	}
+
+	log.Logger().Infof("waiting for job creation of %s/%s", project.FullName, o.branchJobName())
+	var job gojenkins.Job
+	var indexing *jenkinsutil.Indexing
+	found := false
+	err = helpers.Poll(1*time.Second, o.ScanTimeout, fmt.Sprintf("the branch job of %s", project.FullName), func() (bool, error) {
+		job, err = jenkinsClient.GetMultiBranchJob(gitInfo.Organisation, gitInfo.Name, o.branchJobName())
+		if err == nil {
+			found = true
+			return true, nil
+		}
+		if !isNotFound(jenkinsClient, err) {
+			return false, err
+		}
+		indexing, err = jenkinsClient.GetIndexing(project)
+		if err != nil {
+			if isNotFound(jenkinsClient, err) {
+				return false, nil
+			}
+			return false, err
+		}
+		if indexing.Building || indexing.Timestamp <= previousStart {
+			log.Logger().Debugf("not yet available %s/%s", project.FullName, o.branchJobName())
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil || found {
+		return job, err
+	}
+
+	// the indexing may have created the branch job just before it completed
+	job, err = jenkinsClient.GetMultiBranchJob(gitInfo.Organisation, gitInfo.Name, o.branchJobName())
+	if err == nil {
+		return job, nil
+	}
+	scanLog, logErr := jenkinsClient.GetIndexingLog(project)
+	if logErr != nil {
+		log.Logger().Warnf("failed to get the scan log of %s: %s", project.FullName, logErr.Error())
+	}
+	hint := ""
+	if o.PullRequest > 0 {
+		hint = pullRequestDiscoveryHint
+	}
+	return job, fmt.Errorf("the scan of multibranch project %s completed with result %s without creating the job %s.%s The end of the scan log is:\n%s",
+		project.FullName, util.ColorInfo(indexing.Result), util.ColorInfo(o.branchJobName()), hint, tailLines(scanLog, scanLogTailLines))
+}
+
+// tailLines returns the last count lines of the text
+func tailLines(text string, count int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > count {
+		lines = lines[len(lines)-count:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,56 @@
+package trigger
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePullRequest(t *testing.T) {
+	testCases := []struct {
+		name                string
+		pullNumberEnv       string
+		pullRequest         int
+		multiBranchProject  bool
+		organizationFolder  bool
+		expectedPR          int
+		expectedMultiBranch bool
+		expectedJob         string
+		expectErr           bool
+	}{
+		{name: "branch", pullNumberEnv: "45", expectedJob: "master"},
+		{name: "pr flag", pullRequest: 12, expectedPR: 12, expectedMultiBranch: true, expectedJob: "PR-12"},
+		{name: "pr flag over env", pullNumberEnv: "45", pullRequest: 12, multiBranchProject: true, expectedPR: 12, expectedMultiBranch: true, expectedJob: "PR-12"},
+		{name: "multi branch env", pullNumberEnv: "45", multiBranchProject: true, expectedPR: 45, expectedMultiBranch: true, expectedJob: "PR-45"},
+		{name: "organization folder env", pullNumberEnv: "45", organizationFolder: true, expectedPR: 45, expectedJob: "PR-45"},
+		{name: "multi branch without env", multiBranchProject: true, expectedMultiBranch: true, expectedJob: "master"},
+		{name: "invalid env", pullNumberEnv: "abc", multiBranchProject: true, expectErr: true},
+		{name: "negative flag", pullRequest: -1, expectErr: true},
+	}
+	defer os.Unsetenv(PullNumberEnv)
+	for _, tc := range testCases {
+		if tc.pullNumberEnv != "" {
+			require.NoError(t, os.Setenv(PullNumberEnv, tc.pullNumberEnv), "failed to set $%s", PullNumberEnv)
+		} else {
+			require.NoError(t, os.Unsetenv(PullNumberEnv), "failed to unset $%s", PullNumberEnv)
+		}
+
+		o := newTriggerOptions()
+		o.Branch = "master"
+		o.PullRequest = tc.pullRequest
+		o.MultiBranchProject = tc.multiBranchProject
+		o.OrganizationFolder = tc.organizationFolder
+
+		err := o.resolvePullRequest()
+		if tc.expectErr {
+			assert.Error(t, err, "should have failed for %s", tc.name)
+			continue
+		}
+		require.NoError(t, err, "should not have failed for %s", tc.name)
+		assert.Equal(t, tc.expectedPR, o.PullRequest, "pull request for %s", tc.name)
+		assert.Equal(t, tc.expectedMultiBranch, o.MultiBranchProject, "multi branch project for %s", tc.name)
+		assert.Equal(t, tc.expectedJob, o.branchJobName(), "branch job for %s", tc.name)
+	}
+}
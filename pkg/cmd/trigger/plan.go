@@ -0,0 +1,137 @@
+package trigger
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+// TriggerPlan the changes a dry run of the trigger would make in Jenkins
+type TriggerPlan struct {
+	// Server the name of the Jenkins server
+	Server string `json:"server,omitempty"`
+
+	// GitURL the URL of the git repository
+	GitURL string `json:"gitURL,omitempty"`
+
+	// Branch the git branch
+	Branch string `json:"branch,omitempty"`
+
+	// Jenkinsfile the name of the Jenkinsfile
+	Jenkinsfile string `json:"jenkinsfile,omitempty"`
+
+	// JenkinsPath the folder path of the pipeline
+	JenkinsPath string `json:"jenkinsPath,omitempty"`
+
+	// Job the full name of the Jenkins job
+	Job string `json:"job"`
+
+	// Actions the changes which would be made in order
+	Actions []jenkinsutil.DryRunAction `json:"actions"`
+}
+
+// writePlan records the build which would be queued or stopped then writes the plan
+func (o *TriggerOptions) writePlan(dryRun *jenkinsutil.DryRunClient, gitInfo *gits.GitRepository, job gojenkins.Job, params url.Values) error {
+	if o.Cancel {
+		err := o.planCancel(dryRun, job, o.AllRunning)
+		if err != nil {
+			return err
+		}
+	} else {
+		if o.Supersede {
+			err := o.planCancel(dryRun, job, true)
+			if err != nil {
+				return err
+			}
+		}
+		_, err := dryRun.BuildWithParameters(job, params)
+		if err != nil {
+			return err
+		}
+	}
+
+	plan := &TriggerPlan{
+		Server:      o.Result.Server,
+		GitURL:      gitInfo.URL,
+		Branch:      o.Branch,
+		Jenkinsfile: o.Jenkinsfile,
+		JenkinsPath: o.JenkinsPath,
+		Job:         job.FullName,
+		Actions:     dryRun.Actions,
+	}
+	if o.Output != "" {
+		return o.writeOutput(plan)
+	}
+
+	out := o.GetIOFileHandles().Out
+
+	fmt.Fprintf(out, "Jenkins server: %s\n", util.ColorInfo(plan.Server))
+	fmt.Fprintf(out, "Git URL:        %s\n", util.ColorInfo(plan.GitURL))
+	fmt.Fprintf(out, "Branch:         %s\n", util.ColorInfo(plan.Branch))
+	fmt.Fprintf(out, "Jenkinsfile:    %s\n", util.ColorInfo(plan.Jenkinsfile))
+	fmt.Fprintf(out, "Jenkins path:   %s\n", util.ColorInfo(plan.JenkinsPath))
+	fmt.Fprintln(out)
+	for _, a := range plan.Actions {
+		fmt.Fprintf(out, "would %s\n", describeAction(&a))
+		if a.XML != "" {
+			fmt.Fprintln(out, strings.TrimSpace(a.XML))
+		}
+		fmt.Fprintln(out)
+	}
+	return nil
+}
+
+// planCancel records the queued builds which would be removed and the running builds which would be stopped
+func (o *TriggerOptions) planCancel(dryRun *jenkinsutil.DryRunClient, job gojenkins.Job, all bool) error {
+	builds, queueItems, err := o.buildsToCancel(dryRun, job, all)
+	if err != nil {
+		return err
+	}
+	for _, item := range queueItems {
+		err = dryRun.CancelQueueItem(item.ID)
+		if err != nil {
+			return err
+		}
+	}
+	for _, build := range builds {
+		err = dryRun.StopBuild(job, build.Number)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// describeAction returns a description of the dry run action
+func describeAction(a *jenkinsutil.DryRunAction) string {
+	kind := "pipeline"
+	if a.IsFolder() {
+		kind = "folder"
+	}
+	switch a.Action {
+	case jenkinsutil.DryRunCreate:
+		return fmt.Sprintf("create %s %s:", kind, util.ColorInfo(a.Job))
+	case jenkinsutil.DryRunUpdate:
+		return fmt.Sprintf("update %s %s to:", kind, util.ColorInfo(a.Job))
+	case jenkinsutil.DryRunScan:
+		return fmt.Sprintf("scan multi branch project %s", util.ColorInfo(a.Job))
+	case jenkinsutil.DryRunCreateCredential:
+		return fmt.Sprintf("create git credentials %s", util.ColorInfo(a.Credential))
+	case jenkinsutil.DryRunCancelQueueItem:
+		return fmt.Sprintf("remove queued build %d from the queue", a.QueueID)
+	case jenkinsutil.DryRunStop:
+		return fmt.Sprintf("stop build %s #%d", util.ColorInfo(a.Job), a.BuildNumber)
+	case jenkinsutil.DryRunBuild:
+		if len(a.Parameters) > 0 {
+			return fmt.Sprintf("queue a build of %s via buildWithParameters with parameters %s", util.ColorInfo(a.Job), util.ColorInfo(a.Parameters.Encode()))
+		}
+		return fmt.Sprintf("queue a build of %s via build", util.ColorInfo(a.Job))
+	default:
+		return fmt.Sprintf("%s %s", a.Action, a.Job)
+	}
+}
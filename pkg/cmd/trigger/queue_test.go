@@ -0,0 +1,49 @@
+package trigger
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriggerFollowsQueueItemUntilCancelled(t *testing.T) {
+	queueRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/job/myjob/build":
+			w.Header().Set("Location", "http://"+r.Host+"/queue/item/5/")
+			w.WriteHeader(http.StatusCreated)
+
+		case "/queue/item/5/api/json":
+			queueRequests++
+			if queueRequests == 1 {
+				fmt.Fprint(w, `{"id":5,"why":"Waiting for next available executor","executable":null}`)
+				return
+			}
+			fmt.Fprint(w, `{"id":5,"cancelled":true,"executable":null}`)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	jsvc := &jenkinsutil.JenkinsServer{Name: "test", URL: server.URL}
+	client, err := jsvc.CreateClient()
+	require.NoError(t, err, "failed to create client")
+
+	o := newTriggerOptions()
+	job := gojenkins.Job{Name: "myjob", FullName: "myjob", Url: server.URL + "/job/myjob/"}
+	_, err = o.triggerAndWaitForBuildToStart(client, job, nil, 30*time.Second)
+	require.Error(t, err, "should fail when the queued build is cancelled")
+	assert.Contains(t, err.Error(), "the queued build of myjob was cancelled")
+	assert.Equal(t, 2, queueRequests, "should keep polling the queue item while it has no executable")
+	assert.Equal(t, 5, o.Result.QueueID, "queue ID")
+}
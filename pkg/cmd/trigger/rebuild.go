@@ -0,0 +1,172 @@
+package trigger
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/common"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// RebuildOptions contains the command line arguments for this command
+type RebuildOptions struct {
+	TriggerOptions
+
+	JobName string
+}
+
+// OriginalBuild the build which was rebuilt
+type OriginalBuild struct {
+	// Number the number of the original build
+	Number int `json:"number"`
+
+	// URL the URL of the original build
+	URL string `json:"url,omitempty"`
+
+	// Causes the descriptions of why the original build was started
+	Causes []string `json:"causes,omitempty"`
+}
+
+var (
+	rebuildLong = templates.LongDesc(`
+		This command triggers a new build of a job using the same parameters as a previous build
+
+		If no build number is specified the last build of the job is rebuilt. Use --param or --params-file to override
+		any of the original parameters. Parameters whose values are not returned by Jenkins such as passwords are not
+		passed to the new build.
+
+`)
+
+	rebuildExample = templates.Examples(`
+		# rebuilds the last build of a job
+		%s rebuild myowner/myrepo/master
+
+		# rebuilds build 42 overriding a parameter then waits for the result
+		%s rebuild myowner/myrepo/master 42 --param VERSION=1.2.4 --wait
+`)
+)
+
+// NewCmdRebuild creates the new command
+func NewCmdRebuild() (*cobra.Command, *RebuildOptions) {
+	o := &RebuildOptions{}
+	cmd := &cobra.Command{
+		Use:     "rebuild <job> [build-number]",
+		Short:   "triggers a new build of a job with the same parameters as a previous build",
+		Long:    rebuildLong,
+		Example: fmt.Sprintf(rebuildExample, common.BinaryName, common.BinaryName),
+		Args:    cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			common.SetLoggingLevel(cmd)
+			err := o.parseArgs(args)
+			if err == nil {
+				err = o.Run()
+			}
+			common.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", "", "The jenkins namespace")
+	cmd.Flags().StringVarP(&o.JenkinsSelector.DevelopmentJenkinsURL, "dev-jenkins-url", "", "", "Specifies a local URL to access the jenkins server if you are not running this command inside a Kubernetes cluster and don't have Ingress resosurces for the Jenkins server and so cannot use Kubernetes Service discovery. E.g. could be 'http://localhost:8080' if you are using: kubectl port-forward jenkins-server1 8080:8080")
+	o.addBuildFlags(cmd)
+	o.JenkinsSelector.AddFlags(cmd)
+
+	cmd.PersistentFlags().BoolVarP(&o.BatchMode, "batch-mode", "b", os.Getenv("JX_BATCH_MODE") == "true", "Runs in batch mode without prompting for user input")
+	return cmd, o
+}
+
+// parseArgs parses the job name and optional build number arguments
+func (o *RebuildOptions) parseArgs(args []string) error {
+	o.JobName = args[0]
+	if len(args) > 1 {
+		number, err := strconv.Atoi(args[1])
+		if err != nil || number <= 0 {
+			return fmt.Errorf("invalid build number %s", args[1])
+		}
+		o.BuildNumber = number
+	}
+	return nil
+}
+
+// Run implements the command
+func (o *RebuildOptions) Run() error {
+	err := o.validateLogFormat()
+	if err != nil {
+		return err
+	}
+	err = o.setup()
+	if err != nil {
+		return err
+	}
+	serverName, jsvc, err := o.PickCustomJenkinsName(&o.JenkinsSelector, true)
+	if err != nil {
+		return err
+	}
+	o.Result.Server = serverName
+	jenkinsClient, err := jsvc.CreateClient()
+	if err != nil {
+		return err
+	}
+	return o.Rebuild(jenkinsClient)
+}
+
+// Rebuild triggers a new build of the job with the parameters of the original build and any overrides
+func (o *RebuildOptions) Rebuild(jenkinsClient jenkinsutil.Client) error {
+	job, err := jenkinsutil.GetJobByFullName(jenkinsClient, o.JobName)
+	if err != nil {
+		return err
+	}
+	var build gojenkins.Build
+	if o.BuildNumber > 0 {
+		build, err = jenkinsClient.GetBuild(job, o.BuildNumber)
+	} else {
+		build, err = jenkinsClient.GetLastBuild(job)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot find the build of %s to rebuild", o.JobName)
+	}
+	details, err := jenkinsClient.GetBuildDetails(build.Url)
+	if err != nil {
+		return err
+	}
+
+	params, missing := details.Parameters()
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		log.Logger().Warnf("the values of parameters %s of %s #%d are not available so they will use their defaults", strings.Join(missing, ", "), o.JobName, build.Number)
+	}
+	overrides, err := o.buildParameters()
+	if err != nil {
+		return err
+	}
+	params = jenkinsutil.MergeBuildParameters(params, overrides)
+
+	causes := details.CauseDescriptions()
+	o.Result.OriginalBuild = &OriginalBuild{
+		Number: build.Number,
+		URL:    build.Url,
+		Causes: causes,
+	}
+	if len(causes) > 0 {
+		log.Logger().Infof("rebuilding %s #%d which was %s", util.ColorInfo(o.JobName), build.Number, lowerFirst(strings.Join(causes, ", ")))
+	} else {
+		log.Logger().Infof("rebuilding %s #%d", util.ColorInfo(o.JobName), build.Number)
+	}
+	return o.triggerJob(jenkinsClient, job, params)
+}
+
+// lowerFirst lower cases the first character so that cause descriptions like 'Started by user admin' read as a sentence
+func lowerFirst(text string) string {
+	if text == "" {
+		return text
+	}
+	return strings.ToLower(text[:1]) + text[1:]
+}
@@ -0,0 +1,69 @@
+package trigger_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/cmd/trigger"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil/fake"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/magiconair/properties/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebuild(t *testing.T) {
+	buildURL := "https://jenkins.acme.com/job/myowner/job/myrepo/job/master/7/"
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		FolderXMLJobs: []fake.FolderXMLJob{
+			{
+				Folder:  "myowner/job/myrepo",
+				JobName: "master",
+			},
+		},
+		Builds: []gojenkins.Build{
+			{Number: 7, Url: buildURL, Result: "SUCCESS"},
+		},
+		BuildDetails: map[string]*jenkinsutil.BuildDetails{
+			buildURL: {
+				Number: 7,
+				Actions: []jenkinsutil.BuildAction{
+					{
+						Parameters: []jenkinsutil.BuildParameter{
+							{Name: "VERSION", Value: "1.2.3"},
+							{Name: "RELEASE", Value: true},
+							{Name: "TOKEN"},
+						},
+					},
+					{
+						Causes: []jenkinsutil.BuildCause{
+							{ShortDescription: "Started by user admin", UserID: "admin"},
+						},
+					},
+				},
+			},
+		},
+		BuildResult: "SUCCESS",
+	}
+
+	_, o := trigger.NewCmdRebuild()
+	o.JobName = "myowner/myrepo/master"
+	o.BuildNumber = 7
+	o.Params = []string{"VERSION=1.2.4"}
+	o.Wait = true
+
+	err := o.Rebuild(jenkinsClient)
+	require.NoError(t, err, "should not have failed")
+	require.Equal(t, 1, len(jenkinsClient.BuildRequests), "should have a single build request")
+
+	expected := url.Values{
+		"VERSION": []string{"1.2.4"},
+		"RELEASE": []string{"true"},
+	}
+	assert.Equal(t, jenkinsClient.BuildRequests[0].Values, expected, "build parameters")
+	require.NotNil(t, o.Result.OriginalBuild, "original build")
+	assert.Equal(t, o.Result.OriginalBuild.Number, 7, "original build number")
+	assert.Equal(t, o.Result.OriginalBuild.Causes, []string{"Started by user admin"}, "original build causes")
+	assert.Equal(t, o.Result.Result, "SUCCESS", "result")
+}
@@ -0,0 +1,74 @@
+package trigger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ReconcileWarn logs a warning if an existing pipeline differs from the desired configuration
+	ReconcileWarn = "warn"
+
+	// ReconcileUpdate updates an existing pipeline if it differs from the desired configuration
+	ReconcileUpdate = "update"
+
+	// ReconcileFail fails if an existing pipeline differs from the desired configuration
+	ReconcileFail = "fail"
+)
+
+// ReconcileModes the supported values of --reconcile
+var ReconcileModes = []string{ReconcileWarn, ReconcileUpdate, ReconcileFail}
+
+// reconcilePipeline compares the config.xml of the existing pipeline with the desired pipeline XML then
+// warns, updates the pipeline or fails depending on the reconcile mode
+func (o *TriggerOptions) reconcilePipeline(jenkinsClient jenkinsutil.Client, job gojenkins.Job, pipelineXML string) error {
+	desired, err := jenkinsutil.ParsePipelineConfig(pipelineXML)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse the desired pipeline XML")
+	}
+	currentXML, err := jenkinsClient.GetJobConfigXML(job)
+	if err != nil {
+		return o.reconcileError(err)
+	}
+	current, err := jenkinsutil.ParsePipelineConfig(currentXML)
+	if err != nil {
+		return o.reconcileError(errors.Wrapf(err, "failed to parse the config.xml of %s", job.FullName))
+	}
+	diffs := current.Diff(desired)
+	if len(diffs) == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("the pipeline %s has drifted from the desired configuration: %s", job.FullName, strings.Join(diffs, ", "))
+
+	switch o.Reconcile {
+	case ReconcileUpdate:
+		log.Logger().Infof("updating %s as %s", util.ColorInfo(job.FullName), message)
+		err = jenkinsClient.UpdateJobWithXML(job, pipelineXML)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update the pipeline %s", job.FullName)
+		}
+		return nil
+	case ReconcileFail:
+		return fmt.Errorf("%s. Use --reconcile=%s to update it", message, ReconcileUpdate)
+	default:
+		log.Logger().Warnf("%s. Use --reconcile=%s to update it", message, ReconcileUpdate)
+		return nil
+	}
+}
+
+// reconcileError returns the error if the existing pipeline cannot be compared such as when the user cannot read
+// its config.xml or it is not a pipeline job. The error is only logged when warning about drift so that the
+// pipeline can still be triggered
+func (o *TriggerOptions) reconcileError(err error) error {
+	if o.Reconcile != ReconcileWarn {
+		return err
+	}
+	log.Logger().Warnf("cannot check if the pipeline has drifted from the desired configuration: %s", err.Error())
+	return nil
+}
@@ -0,0 +1,123 @@
+package trigger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// OutputJSON outputs the trigger result as JSON
+	OutputJSON = "json"
+
+	// OutputYAML outputs the trigger result as YAML
+	OutputYAML = "yaml"
+)
+
+// OutputFormats the supported output formats
+var OutputFormats = []string{OutputJSON, OutputYAML}
+
+// TriggerResult the machine readable result of triggering a pipeline
+type TriggerResult struct {
+	// Server the name of the Jenkins server
+	Server string `json:"server,omitempty"`
+
+	// Job the full name of the Jenkins job
+	Job string `json:"job"`
+
+	// BuildNumber the number of the triggered build
+	BuildNumber int `json:"buildNumber,omitempty"`
+
+	// BuildURL the URL of the triggered build
+	BuildURL string `json:"buildURL,omitempty"`
+
+	// QueueID the id of the queue item of the triggered build
+	QueueID int `json:"queueId,omitempty"`
+
+	// Building whether the build is still running
+	Building bool `json:"building"`
+
+	// Result the result of the build if it has completed
+	Result string `json:"result,omitempty"`
+
+	// TimedOut whether the build did not complete within the --build-timeout
+	TimedOut bool `json:"timedOut,omitempty"`
+
+	// DurationMillis the duration of the build in milliseconds if it has completed
+	DurationMillis int64 `json:"durationMillis,omitempty"`
+
+	// TriggeredTime when the build was triggered
+	TriggeredTime *time.Time `json:"triggeredTime,omitempty"`
+
+	// StartedTime when the build started
+	StartedTime *time.Time `json:"startedTime,omitempty"`
+
+	// CompletedTime when the build completed
+	CompletedTime *time.Time `json:"completedTime,omitempty"`
+
+	// StoppedBuilds the builds which were cancelled
+	StoppedBuilds []StoppedBuild `json:"stoppedBuilds,omitempty"`
+
+	// CancelledQueueItems the ids of the queued builds which were removed from the queue
+	CancelledQueueItems []int `json:"cancelledQueueItems,omitempty"`
+
+	// Stages the stages of the build if using --stages
+	Stages []jenkinsutil.PipelineStage `json:"stages,omitempty"`
+
+	// OriginalBuild the build which was rebuilt if using rebuild
+	OriginalBuild *OriginalBuild `json:"originalBuild,omitempty"`
+
+	// Artifacts the archived artifacts of the build which were downloaded if using --download-artifacts
+	Artifacts []DownloadedArtifact `json:"artifacts,omitempty"`
+}
+
+// populateBuild updates the result from the given build
+func (r *TriggerResult) populateBuild(build *gojenkins.Build) {
+	r.BuildNumber = build.Number
+	r.BuildURL = build.Url
+	r.Building = build.Building
+	r.Result = build.Result
+	r.DurationMillis = int64(build.Duration)
+	if build.Timestamp > 0 {
+		started := time.Unix(0, int64(build.Timestamp)*int64(time.Millisecond))
+		r.StartedTime = &started
+		if !build.Building {
+			completed := started.Add(time.Duration(build.Duration) * time.Millisecond)
+			r.CompletedTime = &completed
+		}
+	}
+}
+
+// writeResult writes the result in the output format if one is specified
+func (o *TriggerOptions) writeResult() error {
+	if o.Output == "" {
+		return nil
+	}
+	return o.writeOutput(&o.Result)
+}
+
+// writeOutput writes the value to stdout in the output format
+func (o *TriggerOptions) writeOutput(value interface{}) error {
+	var data []byte
+	var err error
+	switch o.Output {
+	case OutputJSON:
+		data, err = json.MarshalIndent(value, "", "  ")
+	case OutputYAML:
+		data, err = yaml.Marshal(value)
+	default:
+		return util.InvalidOption("output", o.Output, OutputFormats)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the output as %s", o.Output)
+	}
+	_, err = fmt.Fprintln(o.GetIOFileHandles().Out, strings.TrimSuffix(string(data), "\n"))
+	return err
+}
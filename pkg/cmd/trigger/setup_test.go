@@ -0,0 +1,62 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultOptionsAreValid(t *testing.T) {
+	testCases := []struct {
+		name      string
+		options   *TriggerOptions
+		logFormat bool
+	}{
+		{"trigger", newTriggerOptions(), true},
+		{"rebuild", newRebuildOptions(), true},
+		{"logs", newLogsOptions(), true},
+		{"stages", newStagesOptions(), false},
+		{"artifacts", newArtifactsOptions(), false},
+	}
+	for _, tc := range testCases {
+		err := tc.options.validateOptions()
+		require.NoError(t, err, "default options of %s should be valid", tc.name)
+		if tc.logFormat {
+			err = tc.options.validateLogFormat()
+			require.NoError(t, err, "default log format of %s should be valid", tc.name)
+		}
+	}
+}
+
+func TestInvalidLogStream(t *testing.T) {
+	o := newTriggerOptions()
+	o.LogStream = "verbose"
+	err := o.validateLogFormat()
+	assert.Error(t, err, "should fail for an invalid --log-stream")
+}
+
+func newTriggerOptions() *TriggerOptions {
+	_, o := NewCmdTrigger()
+	return o
+}
+
+func newRebuildOptions() *TriggerOptions {
+	_, o := NewCmdRebuild()
+	return &o.TriggerOptions
+}
+
+func newLogsOptions() *TriggerOptions {
+	_, o := NewCmdLogs()
+	return &o.TriggerOptions
+}
+
+func newStagesOptions() *TriggerOptions {
+	_, o := NewCmdStages()
+	return &o.TriggerOptions
+}
+
+func newArtifactsOptions() *TriggerOptions {
+	_, o := NewCmdArtifacts()
+	return &o.TriggerOptions
+}
@@ -0,0 +1,227 @@
+package trigger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/common"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/table"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StagesOptions contains the command line arguments for this command
+type StagesOptions struct {
+	TriggerOptions
+
+	JobName string
+}
+
+var (
+	stagesLong = templates.LongDesc(`
+		This command displays the stages of a pipeline build using the pipeline REST API
+
+		If the build is still running the stages are followed as they start and complete until the build completes.
+		Then a summary of the stages is displayed.
+
+`)
+
+	stagesExample = templates.Examples(`
+		# displays the stages of the last build of a job
+		%s stages myowner/myrepo/master
+
+		# displays the stages of build 42 as JSON
+		%s stages myowner/myrepo/master 42 --output json
+`)
+)
+
+// NewCmdStages creates the new command
+func NewCmdStages() (*cobra.Command, *StagesOptions) {
+	o := &StagesOptions{}
+	cmd := &cobra.Command{
+		Use:     "stages <job> [build-number]",
+		Short:   "displays the stages of a pipeline build",
+		Long:    stagesLong,
+		Example: fmt.Sprintf(stagesExample, common.BinaryName, common.BinaryName),
+		Aliases: []string{"stage"},
+		Args:    cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			common.SetLoggingLevel(cmd)
+			err := o.parseArgs(args)
+			if err == nil {
+				err = o.Run()
+			}
+			common.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", "", "The jenkins namespace")
+	cmd.Flags().StringVarP(&o.JenkinsSelector.DevelopmentJenkinsURL, "dev-jenkins-url", "", "", "Specifies a local URL to access the jenkins server if you are not running this command inside a Kubernetes cluster and don't have Ingress resosurces for the Jenkins server and so cannot use Kubernetes Service discovery. E.g. could be 'http://localhost:8080' if you are using: kubectl port-forward jenkins-server1 8080:8080")
+	cmd.Flags().DurationVarP(&o.PollInterval, "poll-interval", "", 2*time.Second, "How often to poll Jenkins when following the stages of a running build")
+	cmd.Flags().DurationVarP(&o.BuildTimeout, "build-timeout", "", common.DurationFromEnv(BuildTimeoutEnv, 100*time.Hour), fmt.Sprintf("How long to follow the stages of a running build. Defaults to $%s if set", BuildTimeoutEnv))
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", fmt.Sprintf("Outputs the stages to stdout in the given format rather than as a table. Supported formats: %s", strings.Join(OutputFormats, ", ")))
+	o.JenkinsSelector.AddFlags(cmd)
+
+	cmd.PersistentFlags().BoolVarP(&o.BatchMode, "batch-mode", "b", os.Getenv("JX_BATCH_MODE") == "true", "Runs in batch mode without prompting for user input")
+	return cmd, o
+}
+
+// parseArgs parses the job name and optional build number arguments
+func (o *StagesOptions) parseArgs(args []string) error {
+	o.JobName = args[0]
+	if len(args) > 1 {
+		number, err := strconv.Atoi(args[1])
+		if err != nil || number <= 0 {
+			return fmt.Errorf("invalid build number %s", args[1])
+		}
+		o.BuildNumber = number
+	}
+	return nil
+}
+
+// Run implements the command
+func (o *StagesOptions) Run() error {
+	err := o.setup()
+	if err != nil {
+		return err
+	}
+	_, jsvc, err := o.PickCustomJenkinsName(&o.JenkinsSelector, true)
+	if err != nil {
+		return err
+	}
+	jenkinsClient, err := jsvc.CreateClient()
+	if err != nil {
+		return err
+	}
+	return o.ShowStages(jenkinsClient)
+}
+
+// ShowStages follows the stages of the build if it is running then displays a summary of its stages
+func (o *StagesOptions) ShowStages(jenkinsClient jenkinsutil.Client) error {
+	job, err := jenkinsutil.GetJobByFullName(jenkinsClient, o.JobName)
+	if err != nil {
+		return err
+	}
+	var build gojenkins.Build
+	if o.BuildNumber > 0 {
+		build, err = jenkinsClient.GetBuild(job, o.BuildNumber)
+	} else {
+		build, err = jenkinsClient.GetLastBuild(job)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot find the build of %s", o.JobName)
+	}
+
+	if build.Building {
+		o.Stages = true
+		build, err = o.waitForBuildToComplete(jenkinsClient, job, build, o.BuildTimeout)
+		if err != nil {
+			return errors.Wrapf(err, "cannot wait for build %s/%d", job.FullName, build.Number)
+		}
+	}
+
+	run, err := jenkinsClient.GetPipelineRun(build.Url)
+	if err != nil {
+		return err
+	}
+	if o.Output != "" {
+		return o.writeOutput(run.Stages)
+	}
+	writeStageTable(o.GetIOFileHandles().Out, run.Stages)
+	return nil
+}
+
+// stageTracker tracks the status of the stages of a build so that the stages which start or complete can be reported
+type stageTracker struct {
+	statuses map[string]string
+}
+
+// update returns the stages whose status has changed since the last update
+func (t *stageTracker) update(run *jenkinsutil.PipelineRun) []jenkinsutil.PipelineStage {
+	if t.statuses == nil {
+		t.statuses = map[string]string{}
+	}
+	var answer []jenkinsutil.PipelineStage
+	for _, stage := range run.Stages {
+		key := stage.ID
+		if key == "" {
+			key = stage.Name
+		}
+		if t.statuses[key] != stage.Status {
+			t.statuses[key] = stage.Status
+			answer = append(answer, stage)
+		}
+	}
+	return answer
+}
+
+// logStageTransitions logs the stages of the build which have started or completed since the last poll
+func (o *TriggerOptions) logStageTransitions(jenkins jenkinsutil.Client, job gojenkins.Job, build *gojenkins.Build, tracker *stageTracker) {
+	run, err := jenkins.GetPipelineRun(build.Url)
+	if err != nil {
+		log.Logger().Debugf("failed to get the stages of %s #%d: %s", job.FullName, build.Number, err.Error())
+		return
+	}
+	for _, stage := range tracker.update(run) {
+		prefix := fmt.Sprintf("%s #%d stage %s", job.FullName, build.Number, util.ColorInfo(stage.Name))
+		switch stage.Status {
+		case "IN_PROGRESS":
+			log.Logger().Infof("%s started", prefix)
+		case "PAUSED_PENDING_INPUT":
+			log.Logger().Infof("%s is waiting for input", prefix)
+		case "NOT_EXECUTED":
+			log.Logger().Infof("%s was skipped", prefix)
+		default:
+			log.Logger().Infof("%s completed with status %s in %s", prefix, stageStatus(stage.Status), stageDuration(&stage))
+		}
+	}
+}
+
+// writeStageSummary writes a table of the stages of the completed build and adds them to the result
+func (o *TriggerOptions) writeStageSummary(jenkins jenkinsutil.Client, job gojenkins.Job, build *gojenkins.Build) error {
+	run, err := jenkins.GetPipelineRun(build.Url)
+	if err != nil {
+		return errors.Wrapf(err, "cannot get the stages of %s #%d", job.FullName, build.Number)
+	}
+	o.Result.Stages = run.Stages
+	writeStageTable(o.tailWriter(), run.Stages)
+	return nil
+}
+
+// writeStageTable writes the stages as a table
+func writeStageTable(out io.Writer, stages []jenkinsutil.PipelineStage) {
+	t := table.CreateTable(out)
+	t.AddRow("STAGE", "STATUS", "DURATION")
+	for i := range stages {
+		stage := &stages[i]
+		t.AddRow(stage.Name, stageStatus(stage.Status), stageDuration(stage))
+	}
+	t.Render()
+}
+
+// stageStatus returns the status colored by whether the stage succeeded
+func stageStatus(status string) string {
+	switch status {
+	case "SUCCESS":
+		return util.ColorInfo(status)
+	case "FAILED", "ABORTED":
+		return util.ColorError(status)
+	case "UNSTABLE":
+		return util.ColorWarning(status)
+	default:
+		return status
+	}
+}
+
+func stageDuration(stage *jenkinsutil.PipelineStage) string {
+	return (time.Duration(stage.DurationMillis) * time.Millisecond).Round(time.Second).String()
+}
@@ -0,0 +1,63 @@
+package trigger_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/cmd/trigger"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil/fake"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/magiconair/properties/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowStages(t *testing.T) {
+	buildURL := "https://jenkins.acme.com/job/myowner/job/myrepo/job/master/7/"
+	stages := []jenkinsutil.PipelineStage{
+		{ID: "6", Name: "Build", Status: "SUCCESS", DurationMillis: 62000},
+		{ID: "12", Name: "Test", Status: "FAILED", DurationMillis: 5000},
+	}
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		FolderXMLJobs: []fake.FolderXMLJob{
+			{
+				Folder:  "myowner/job/myrepo",
+				JobName: "master",
+			},
+		},
+		Builds: []gojenkins.Build{
+			{Number: 7, Url: buildURL, Result: "FAILURE"},
+		},
+		PipelineRuns: map[string]*jenkinsutil.PipelineRun{
+			buildURL: {ID: "7", Status: "FAILED", Stages: stages},
+		},
+	}
+
+	tmpDir, err := ioutil.TempDir("", "test-stages-output-")
+	require.NoError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(tmpDir)
+	out, err := os.Create(filepath.Join(tmpDir, "out.json"))
+	require.NoError(t, err, "failed to create output file")
+	defer out.Close()
+
+	_, o := trigger.NewCmdStages()
+	o.IOFileHandles = &util.IOFileHandles{Out: out, Err: os.Stderr, In: os.Stdin}
+	o.JobName = "myowner/myrepo/master"
+	o.BuildNumber = 7
+	o.Output = "json"
+
+	err = o.ShowStages(jenkinsClient)
+	require.NoError(t, err, "should not have failed")
+
+	data, err := ioutil.ReadFile(out.Name())
+	require.NoError(t, err, "failed to read output")
+	var actual []jenkinsutil.PipelineStage
+	err = json.Unmarshal(data, &actual)
+	require.NoError(t, err, "failed to parse output %s", string(data))
+	assert.Equal(t, actual, stages, "stages")
+}
@@ -0,0 +1,63 @@
+package trigger
+
+import (
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/common"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/jenkins"
+)
+
+// loadRepoConfig defaults any options which are not specified from the repository configuration file
+func (o *TriggerOptions) loadRepoConfig() error {
+	config, err := common.LoadRepoConfig(o.Dir)
+	if err != nil {
+		return err
+	}
+	if o.JobTemplate == "" {
+		o.JobTemplate = config.JobTemplate
+	}
+	if o.FolderTemplate == "" {
+		o.FolderTemplate = config.FolderTemplate
+	}
+	return nil
+}
+
+// folderXML returns the XML to create a folder using the folder template if there is one
+func (o *TriggerOptions) folderXML(gitInfo *gits.GitRepository, name string, fullName string, jobURL string) (string, error) {
+	if o.FolderTemplate == "" {
+		return jenkins.CreateFolderXML(jobURL, name), nil
+	}
+	return jenkinsutil.RenderJobTemplate(o.FolderTemplate, o.jobTemplateData(gitInfo, name, fullName, jobURL))
+}
+
+// pipelineXML returns the XML to create the pipeline using the job template if there is one
+func (o *TriggerOptions) pipelineXML(gitInfo *gits.GitRepository, name string, fullName string, jobURL string) (string, error) {
+	pipelineXML := ""
+	if o.JobTemplate == "" {
+		pipelineXML = jenkins.CreatePipelineXML(gitInfo.URL, o.Branch, o.Jenkinsfile)
+	} else {
+		var err error
+		pipelineXML, err = jenkinsutil.RenderJobTemplate(o.JobTemplate, o.jobTemplateData(gitInfo, name, fullName, jobURL))
+		if err != nil {
+			return "", err
+		}
+	}
+	if o.GitCredentialsID != "" {
+		pipelineXML = jenkinsutil.SetPipelineCredentialsID(pipelineXML, o.GitCredentialsID)
+	}
+	return pipelineXML, nil
+}
+
+func (o *TriggerOptions) jobTemplateData(gitInfo *gits.GitRepository, name string, fullName string, jobURL string) *jenkinsutil.JobTemplateData {
+	return &jenkinsutil.JobTemplateData{
+		GitURL:        gitInfo.URL,
+		CredentialsID: o.GitCredentialsID,
+		Branch:        o.Branch,
+		Jenkinsfile:   o.Jenkinsfile,
+		Owner:         gitInfo.Organisation,
+		Repo:          gitInfo.Name,
+		JobName:       name,
+		FullName:      fullName,
+		URL:           jobURL,
+	}
+}
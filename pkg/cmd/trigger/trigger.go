@@ -2,6 +2,7 @@ package trigger
 
 import (
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"strings"
@@ -12,7 +13,6 @@ import (
 	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
 	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil/factory"
 	gojenkins "github.com/jenkins-x/golang-jenkins"
-	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 
 	"github.com/jenkins-x/jx-logging/pkg/log"
@@ -27,26 +27,103 @@ import (
 // TriggerOptions contains the command line arguments for this command
 type TriggerOptions struct {
 	jenkinsutil.JenkinsOptions
-	Namespace          string
-	MultiBranchProject bool
-	Dir                string
-	Jenkinsfile        string
-	JenkinsPath        string
-	JenkinsSelector    jenkinsutil.JenkinsSelectorOptions
-	Branch             string
-	Tail               bool
-	Cancel             bool
+	Namespace            string
+	KubeNamespace        string
+	MultiBranchProject   bool
+	OrganizationFolder   bool
+	PullRequest          int
+	Dir                  string
+	Jenkinsfile          string
+	JenkinsPath          string
+	JenkinsSelector      jenkinsutil.JenkinsSelectorOptions
+	Branch               string
+	Tail                 bool
+	Cancel               bool
+	BuildNumber          int
+	AllRunning           bool
+	Supersede            bool
+	LogFile              string
+	LogStream            string
+	DownloadArtifacts    string
+	ArtifactGlobs        []string
+	LogPrefix            bool
+	LogTimestamps        bool
+	StripANSI            bool
+	ConsoleNotes         string
+	Stages               bool
+	Params               []string
+	ParamsFile           string
+	Output               string
+	UnstableOK           bool
+	Wait                 bool
+	PollInterval         time.Duration
+	ProgressInterval     time.Duration
+	StartTimeout         time.Duration
+	BuildTimeout         time.Duration
+	ScanTimeout          time.Duration
+	CancelTimeout        time.Duration
+	AbortOnTimeout       bool
+	Manifest             string
+	Parallel             int
+	Reconcile            string
+	DryRun               bool
+	JobTemplate          string
+	FolderTemplate       string
+	GitCredentialsID     string
+	GitCredentialsSecret string
+
+	Result TriggerResult
+
+	// folderLocks serializes creating the jobs in each owner folder when triggering a manifest concurrently
+	folderLocks *folderLocks
 }
 
+const (
+	// StartTimeoutEnv the environment variable for the default --start-timeout
+	StartTimeoutEnv = "TRIGGER_START_TIMEOUT"
+
+	// BuildTimeoutEnv the environment variable for the default --build-timeout
+	BuildTimeoutEnv = "TRIGGER_BUILD_TIMEOUT"
+
+	// ScanTimeoutEnv the environment variable for the default --scan-timeout
+	ScanTimeoutEnv = "TRIGGER_SCAN_TIMEOUT"
+
+	// CancelTimeoutEnv the environment variable for the default --cancel-timeout
+	CancelTimeoutEnv = "TRIGGER_CANCEL_TIMEOUT"
+
+	// AbortOnTimeoutEnv the environment variable for the default --abort-on-timeout
+	AbortOnTimeoutEnv = "TRIGGER_ABORT_ON_TIMEOUT"
+)
+
 var (
 	triggerLong = templates.LongDesc(`
 		This command triggers the Jenkinsfile in the current directory in a Jenkins server
 
+		When waiting for the build result the exit code is 0 for success, 1 for a tool or connection error, 2 for failure,
+		3 for unstable, 4 for aborted, 5 for not built and 6 if it timed out waiting for Jenkins.
+
+		Use --manifest to trigger many jobs or git repositories at once. The exit code is then the highest exit code of all the builds.
+
 `)
 
 	triggerExample = templates.Examples(`
 		# triggers the Jenkinsfile in the current directory in a Jenkins server
 		%s
+
+		# triggers a parameterized pipeline
+		%s --param VERSION=1.2.3 --param TARGETS=linux --param TARGETS=darwin
+
+		# triggers a parameterized pipeline loading the parameters from a file
+		%s --params-file params.yaml --param VERSION=1.2.3
+
+		# triggers the pipeline and outputs the build details as JSON
+		%s --output json
+
+		# displays what would be created and triggered without changing anything
+		%s --dry-run
+
+		# triggers all the pipelines in a manifest file 4 at a time and waits for their results
+		%s --manifest triggers.yaml --parallel 4
 `)
 )
 
@@ -57,23 +134,38 @@ func NewCmdTrigger() (*cobra.Command, *TriggerOptions) {
 		Use:     "trigger",
 		Short:   "triggers the Jenkinsfile in the current directory in a Jenkins server installed via the Jenkins Operator",
 		Long:    triggerLong,
-		Example: fmt.Sprintf(triggerExample, common.BinaryName),
+		Example: fmt.Sprintf(triggerExample, common.BinaryName, common.BinaryName, common.BinaryName, common.BinaryName, common.BinaryName, common.BinaryName),
 		Run: func(cmd *cobra.Command, args []string) {
 			common.SetLoggingLevel(cmd)
 			err := o.Run()
-			helper.CheckErr(err)
+			common.CheckErr(err)
 		},
 	}
 
 	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", "", "The jenkins namespace")
-	cmd.Flags().BoolVarP(&o.MultiBranchProject, "multi-branch-project", "", false, "Use a Multi Branch Project in Jenkins")
+	cmd.Flags().BoolVarP(&o.MultiBranchProject, "multi-branch-project", "", false, "Use a Multi Branch Project in Jenkins. The owner folder and multi branch project are created if they do not exist")
+	cmd.Flags().BoolVarP(&o.OrganizationFolder, "organization-folder", "", false, "Use the multi branch project in the GitHub or Bitbucket organization folder for the git owner in Jenkins. The organization folder is scanned if it does not contain the repository")
+	cmd.Flags().IntVarP(&o.PullRequest, "pr", "", 0, fmt.Sprintf("The number of the pull request whose PR-N job to trigger in the multi branch project. Implies --multi-branch-project. Defaults to $%s when using --multi-branch-project or --organization-folder. Multi branch projects created by tp only discover branches so pull request discovery must be configured on the project", PullNumberEnv))
 	cmd.Flags().StringVarP(&o.Dir, "dir", "d", ".", "the directory to look for the Jenkisnfile inside")
 	cmd.Flags().StringVarP(&o.Jenkinsfile, "jenkinsfile", "f", jenkinsfile.Name, "The name of the Jenkinsfile to use")
 	cmd.Flags().StringVarP(&o.JenkinsPath, "jenkins-path", "p", "", "The Jenkins folder path to create the pipeline inside. If not specified it defaults to the git 'owner/repoName/branch'")
 	cmd.Flags().StringVarP(&o.JenkinsSelector.DevelopmentJenkinsURL, "dev-jenkins-url", "", "", "Specifies a local URL to access the jenkins server if you are not running this command inside a Kubernetes cluster and don't have Ingress resosurces for the Jenkins server and so cannot use Kubernetes Service discovery. E.g. could be 'http://localhost:8080' if you are using: kubectl port-forward jenkins-server1 8080:8080")
 	cmd.Flags().StringVarP(&o.Branch, "branch", "", "", "the branch to trigger a build")
-	cmd.Flags().BoolVarP(&o.Tail, "tail", "t", false, "Tails the build log to the current terminal")
-	cmd.Flags().BoolVarP(&o.Cancel, "cancel", "", false, "Cancel last build")
+	cmd.Flags().BoolVarP(&o.Cancel, "cancel", "", false, "Cancels the last build and removes the queued builds of the job from the queue")
+	cmd.Flags().IntVarP(&o.BuildNumber, "build-number", "", 0, "The number of the build to cancel when using --cancel")
+	cmd.Flags().BoolVarP(&o.Supersede, "supersede", "", false, "Cancels all running builds of the job and removes its queued builds from the queue before triggering the new build")
+	cmd.Flags().BoolVarP(&o.AllRunning, "all-running", "", false, "Cancels all running builds of the job and removes its queued builds from the queue when using --cancel")
+	cmd.Flags().DurationVarP(&o.ScanTimeout, "scan-timeout", "", common.DurationFromEnv(ScanTimeoutEnv, 5*time.Minute), fmt.Sprintf("How long to wait for the multi branch project scan to create the branch job. Defaults to $%s if set", ScanTimeoutEnv))
+	cmd.Flags().DurationVarP(&o.CancelTimeout, "cancel-timeout", "", common.DurationFromEnv(CancelTimeoutEnv, 5*time.Minute), fmt.Sprintf("How long to wait for a cancelled build to stop. Defaults to $%s if set", CancelTimeoutEnv))
+	o.addBuildFlags(cmd)
+	cmd.Flags().StringVarP(&o.Reconcile, "reconcile", "", ReconcileWarn, fmt.Sprintf("What to do if an existing pipeline's git URL, git credentials, branch or Jenkinsfile differs from the desired configuration, or its description, build parameters, builds to keep or lightweight checkout differ from those set by the job template. Other settings are not compared. Supported values: %s", strings.Join(ReconcileModes, ", ")))
+	cmd.Flags().StringVarP(&o.JobTemplate, "job-template", "", "", fmt.Sprintf("A Go template file used to create the pipeline XML. Defaults to the jobTemplate in the %s file of the repository", common.RepoConfigFileName))
+	cmd.Flags().StringVarP(&o.FolderTemplate, "folder-template", "", "", fmt.Sprintf("A Go template file used to create the folder XML. Defaults to the folderTemplate in the %s file of the repository", common.RepoConfigFileName))
+	cmd.Flags().StringVarP(&o.GitCredentialsID, "git-credentials-id", "", "", "The id of the Jenkins credentials used to clone the git repository of the pipeline. Defaults to the --git-credentials-secret name if specified")
+	cmd.Flags().StringVarP(&o.GitCredentialsSecret, "git-credentials-secret", "", "", "The name of a Kubernetes Secret containing the git username and password used to create the Jenkins credentials if they do not exist")
+	cmd.Flags().BoolVarP(&o.DryRun, "dry-run", "", false, "Displays the folders and pipeline which would be created along with their XML and the build which would be queued without changing anything in Jenkins")
+	cmd.Flags().StringVarP(&o.Manifest, "manifest", "m", "", "A YAML file listing the jobs or git repositories to trigger. The pipelines are triggered concurrently and waited for then the results are displayed as a table")
+	cmd.Flags().IntVarP(&o.Parallel, "parallel", "", 4, "The maximum number of pipelines to trigger and wait for at the same time when using --manifest")
 	o.JenkinsSelector.AddFlags(cmd)
 
 	defaultBatchMode := false
@@ -84,18 +176,118 @@ func NewCmdTrigger() (*cobra.Command, *TriggerOptions) {
 	return cmd, o
 }
 
-// Run implements the command
-func (o *TriggerOptions) Run() error {
-	var err error
+// addBuildFlags adds the flags for the parameters of the triggered build and how to wait for its result
+func (o *TriggerOptions) addBuildFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&o.Tail, "tail", "t", false, "Tails the build log to the current terminal")
+	cmd.Flags().StringVarP(&o.LogFile, "log-file", "", "", "Writes the full build log to the given file while tailing the build log. Implies --tail")
+	o.addLogFormatFlags(cmd)
+	cmd.Flags().StringArrayVarP(&o.Params, "param", "", nil, "A build parameter of the form KEY=VALUE. Repeat the flag to pass multiple parameters or multiple values for the same KEY. Use KEY= for an empty value")
+	cmd.Flags().StringVarP(&o.ParamsFile, "params-file", "", "", "A YAML, JSON or Java properties file containing the build parameters. Values can refer to environment variables via ${NAME}. Any --param values override the values in the file")
+	cmd.Flags().BoolVarP(&o.Wait, "wait", "w", false, "Waits for the build to complete without tailing the build log")
+	cmd.Flags().BoolVarP(&o.Stages, "stages", "", false, "Logs the stages of the build as they start and complete while waiting for the build then displays a summary of the stages. Implies --wait unless --tail is used")
+	cmd.Flags().StringVarP(&o.DownloadArtifacts, "download-artifacts", "", "", "Downloads the archived artifacts of the build into the given directory once the build completes. Implies --wait unless --tail is used. Artifacts returned without a Content-Length are not verified")
+	cmd.Flags().StringArrayVarP(&o.ArtifactGlobs, "artifact-glob", "", nil, "Only downloads the artifacts whose relative path matches the glob pattern such as '**/*.jar'. Repeat the flag to match multiple patterns")
+	cmd.Flags().DurationVarP(&o.PollInterval, "poll-interval", "", 2*time.Second, "How often to poll Jenkins when waiting for the build to complete")
+	cmd.Flags().DurationVarP(&o.ProgressInterval, "progress-interval", "", 30*time.Second, "How often to log the progress of the build when waiting for it to complete")
+	cmd.Flags().BoolVarP(&o.UnstableOK, "unstable-ok", "", false, "Treats an UNSTABLE build result as success when waiting for the build result")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", fmt.Sprintf("Outputs the result of the trigger to stdout in the given format. The log is written to stderr. Supported formats: %s", strings.Join(OutputFormats, ", ")))
+	cmd.Flags().DurationVarP(&o.StartTimeout, "start-timeout", "", common.DurationFromEnv(StartTimeoutEnv, 5*time.Minute), fmt.Sprintf("How long to wait for the build to start. Defaults to $%s if set", StartTimeoutEnv))
+	cmd.Flags().DurationVarP(&o.BuildTimeout, "build-timeout", "", common.DurationFromEnv(BuildTimeoutEnv, 100*time.Hour), fmt.Sprintf("How long to wait for the build to complete when using --tail or --wait. Defaults to $%s if set", BuildTimeoutEnv))
+	cmd.Flags().BoolVarP(&o.AbortOnTimeout, "abort-on-timeout", "", os.Getenv(AbortOnTimeoutEnv) == "true", fmt.Sprintf("Stops the build in Jenkins if the --build-timeout expires. Defaults to $%s if set", AbortOnTimeoutEnv))
+}
+
+// addLogFormatFlags adds the flags to format the build log written to the terminal
+func (o *TriggerOptions) addLogFormatFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.LogStream, "log-stream", "", LogStreamFull, fmt.Sprintf("How much of the build log to write to the terminal. Supported values: %s", strings.Join(LogStreams, ", ")))
+	cmd.Flags().BoolVarP(&o.LogPrefix, "log-prefix", "", false, "Prefixes each line of the build log with [job#build]")
+	cmd.Flags().BoolVarP(&o.LogTimestamps, "log-timestamps", "", false, "Prefixes each line of the build log with the local time it was received")
+	cmd.Flags().BoolVarP(&o.StripANSI, "strip-ansi", "", false, "Removes ANSI escape codes such as colours from the build log")
+	cmd.Flags().StringVarP(&o.ConsoleNotes, "console-notes", "", jenkinsutil.ConsoleNotesStrip, fmt.Sprintf("How to handle the hidden console note annotations Jenkins embeds in the build log. Supported values: %s", strings.Join(jenkinsutil.ConsoleNotesModes, ", ")))
+}
+
+// setup validates the options then creates the client factory
+func (o *TriggerOptions) setup() error {
+	err := o.validateOptions()
+	if err != nil {
+		return err
+	}
 	o.ClientFactory, err = factory.NewClientFactory()
 	if err != nil {
 		return err
 	}
+	o.KubeNamespace = o.ClientFactory.Namespace
 	o.ClientFactory.Namespace = o.Namespace
 	o.ClientFactory.Batch = o.BatchMode
 	o.ClientFactory.DevelopmentJenkinsURL = o.JenkinsSelector.DevelopmentJenkinsURL
+	return nil
+}
+
+// validateOptions validates the output format and defaults the options implied by other options. It is shared by
+// all the commands so it must only validate the flags which every command registers
+func (o *TriggerOptions) validateOptions() error {
+	if o.Output != "" {
+		if util.StringArrayIndex(OutputFormats, o.Output) < 0 {
+			return util.InvalidOption("output", o.Output, OutputFormats)
+		}
+		// lets keep stdout for the result
+		log.Logger().Logger.SetOutput(o.GetIOFileHandles().Err)
+	}
+	if o.LogFile != "" {
+		o.Tail = true
+	}
+	if (o.Stages || o.DownloadArtifacts != "") && !o.Tail {
+		o.Wait = true
+	}
+	return nil
+}
+
+// validateLogFormat validates the flags added by addLogFormatFlags
+func (o *TriggerOptions) validateLogFormat() error {
+	if util.StringArrayIndex(LogStreams, o.LogStream) < 0 {
+		return util.InvalidOption("log-stream", o.LogStream, LogStreams)
+	}
+	if util.StringArrayIndex(jenkinsutil.ConsoleNotesModes, o.ConsoleNotes) < 0 {
+		return util.InvalidOption("console-notes", o.ConsoleNotes, jenkinsutil.ConsoleNotesModes)
+	}
+	return nil
+}
+
+// Run implements the command
+func (o *TriggerOptions) Run() error {
+	err := o.validateLogFormat()
+	if err != nil {
+		return err
+	}
+	err = o.setup()
+	if err != nil {
+		return err
+	}
+	if util.StringArrayIndex(ReconcileModes, o.Reconcile) < 0 {
+		return util.InvalidOption("reconcile", o.Reconcile, ReconcileModes)
+	}
+	if o.Cancel && o.Supersede {
+		return fmt.Errorf("--supersede cannot be used with --cancel")
+	}
+	if o.GitCredentialsID == "" {
+		o.GitCredentialsID = o.GitCredentialsSecret
+	}
+	if o.Manifest != "" {
+		if o.DryRun {
+			return fmt.Errorf("--dry-run cannot be used with --manifest")
+		}
+		return o.runManifest()
+	}
+	err = o.resolvePullRequest()
+	if err != nil {
+		return err
+	}
 
-	jenkinsClient, err := o.CreateJenkinsClientFromSelector(&o.JenkinsSelector)
+	serverName, jsvc, err := o.PickCustomJenkinsName(&o.JenkinsSelector, true)
+	if err != nil {
+		return err
+	}
+	o.Result.Server = serverName
+	jenkinsClient, err := jsvc.CreateClient()
 	if err != nil {
 		return err
 	}
@@ -109,6 +301,10 @@ func (o *TriggerOptions) Run() error {
 	if o.Jenkinsfile == "" {
 		o.Jenkinsfile = jenkinsfile.Name
 	}
+	err = o.loadRepoConfig()
+	if err != nil {
+		return err
+	}
 	gitInfo, err := o.FindGitInfo(o.Dir)
 	if err != nil {
 		return err
@@ -132,44 +328,178 @@ func (o *TriggerOptions) Run() error {
 }
 
 // TriggerPipeline trigger a build based on the current git workspace
-func (o *TriggerOptions) TriggerPipeline(jenkinsClient gojenkins.JenkinsClient, gitInfo *gits.GitRepository) error {
+func (o *TriggerOptions) TriggerPipeline(jenkinsClient jenkinsutil.Client, gitInfo *gits.GitRepository) error {
+	// a dry run uses the same code but records the changes to Jenkins rather than making them
+	var dryRun *jenkinsutil.DryRunClient
+	if o.DryRun {
+		dryRun = jenkinsutil.NewDryRunClient(jenkinsClient)
+		jenkinsClient = dryRun
+	}
+
+	params, err := o.buildParameters()
+	if err != nil {
+		return err
+	}
+
+	err = o.ensureGitCredentials(jenkinsClient)
+	if err != nil {
+		return err
+	}
+
 	job, err := o.getOrCreatePipelineFactory(jenkinsClient, gitInfo)()
 	if err != nil {
 		return errors.Wrapf(err, "cannot create pipeline for %s", job.FullName)
 	}
 
+	if dryRun != nil {
+		return o.writePlan(dryRun, gitInfo, job, params)
+	}
+
 	if o.Cancel {
-		return o.cancelLastBuild(jenkinsClient, job, time.Minute*5)
+		o.Result.Job = job.FullName
+		err = o.cancelBuilds(jenkinsClient, job, o.AllRunning)
+		if err != nil {
+			return errors.Wrapf(err, "cannot cancel builds of %s", job.FullName)
+		}
+		return o.writeResult()
 	}
+	return o.triggerJob(jenkinsClient, job, params)
+}
 
-	build, err := o.triggerAndWaitForBuildToStart(jenkinsClient, job, time.Minute*5)
+// triggerJob triggers a build of the job with the given parameters then tails or waits for the build if required
+func (o *TriggerOptions) triggerJob(jenkinsClient jenkinsutil.Client, job gojenkins.Job, params url.Values) error {
+	o.Result.Job = job.FullName
+	if o.Supersede {
+		err := o.cancelBuilds(jenkinsClient, job, true)
+		if err != nil {
+			return errors.Wrapf(err, "cannot cancel the builds superseded by the new build of %s", job.FullName)
+		}
+	}
+	build, err := o.triggerAndWaitForBuildToStart(jenkinsClient, job, params, o.StartTimeout)
 	if err != nil {
 		return errors.Wrapf(err, "cannot trigger build for %s", job.FullName)
 	}
-	if !o.Tail {
-		return err
+	o.Result.populateBuild(&build)
+
+	switch {
+	case o.Tail:
+		err = o.tailBuild(jenkinsClient, job, &build)
+		if err != nil {
+			return o.incompleteBuildError(jenkinsClient, job, &build, errors.Wrapf(err, "cannot tail build for %s/%d", job.FullName, build.Number))
+		}
+
+		build, err = jenkinsClient.GetBuild(job, build.Number)
+		if err != nil {
+			return errors.Wrapf(err, "cannot state build for %s", job.FullName)
+		}
+	case o.Wait:
+		build, err = o.waitForBuildToComplete(jenkinsClient, job, build, o.BuildTimeout)
+		if err != nil {
+			return o.incompleteBuildError(jenkinsClient, job, &build, errors.Wrapf(err, "cannot wait for build %s/%d", job.FullName, build.Number))
+		}
+	}
+	if o.Stages && (o.Tail || o.Wait) {
+		summaryErr := o.writeStageSummary(jenkinsClient, job, &build)
+		if summaryErr != nil {
+			log.Logger().Warnf("failed to display the stages: %s", summaryErr.Error())
+		}
+	}
+	if o.Tail || o.Wait {
+		o.Result.populateBuild(&build)
+		err = common.BuildResultError(job.FullName, build.Number, build.Result, o.UnstableOK)
+		if o.DownloadArtifacts != "" {
+			downloadErr := o.downloadArtifacts(jenkinsClient, job, &build, o.DownloadArtifacts)
+			if downloadErr != nil && err != nil {
+				log.Logger().Warnf("failed to download the artifacts of %s #%d: %s", job.FullName, build.Number, downloadErr.Error())
+			} else if downloadErr != nil {
+				err = errors.Wrapf(downloadErr, "cannot download the artifacts of %s/%d", job.FullName, build.Number)
+			}
+		}
 	}
 
-	err = o.JenkinsOptions.TailJenkinsBuildLog(&o.JenkinsSelector, job.FullName, &build)
-	if err != nil {
-		return errors.Wrapf(err, "cannot tail build for %s/%d", job.FullName, build.Number)
+	outputErr := o.writeResult()
+	if err == nil {
+		err = outputErr
 	}
+	return err
+}
 
-	build, err = jenkinsClient.GetBuild(job, build.Number)
+// tailBuild tails the build log to the terminal and the log file if specified until the build completes. The log
+// file is flushed and closed even if the tail times out or the command is interrupted
+func (o *TriggerOptions) tailBuild(jenkinsClient jenkinsutil.Client, job gojenkins.Job, build *gojenkins.Build) error {
+	out, err := o.tailOutput(job.FullName, build.Number)
 	if err != nil {
-		return errors.Wrapf(err, "cannot state build for %s", job.FullName)
+		return err
+	}
+	stop := o.closeOnInterrupt(out, job.FullName)
+	err = o.TailBuildLog(jenkinsClient, job.FullName, build, out, o.BuildTimeout)
+	stop()
+	closeErr := out.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// incompleteBuildError writes the result of a build which could not be followed until it completed so that the
+// job and build number are still output then returns the error
+func (o *TriggerOptions) incompleteBuildError(jenkins jenkinsutil.Client, job gojenkins.Job, build *gojenkins.Build, err error) error {
+	err = o.buildTimeoutError(jenkins, job, build, err)
+	o.Result.TimedOut = common.IsTimeout(err)
+	outputErr := o.writeResult()
+	if outputErr != nil {
+		log.Logger().Warnf("failed to write the result: %s", outputErr.Error())
 	}
-	if build.Result != "SUCCESS" {
-		message := fmt.Sprintf("build %s/%d result is %s", job.FullName, build.Number, build.Result)
-		err = errors.New(message)
+	return err
+}
+
+// buildTimeoutError stops the build if the error is a timeout and --abort-on-timeout is enabled
+func (o *TriggerOptions) buildTimeoutError(jenkins jenkinsutil.Client, job gojenkins.Job, build *gojenkins.Build, err error) error {
+	if !o.AbortOnTimeout || !common.IsTimeout(err) {
+		return err
+	}
+	log.Logger().Warnf("stopping build %s #%d as it did not complete within %s", job.FullName, build.Number, o.BuildTimeout.String())
+	stopErr := jenkins.StopBuild(job, build.Number)
+	if stopErr != nil {
+		log.Logger().Warnf("failed to stop build %s #%d: %s", job.FullName, build.Number, stopErr.Error())
 	}
 	return err
 }
 
+// tailWriter returns the writer for the tailed build log which is stderr if the result is output to stdout
+func (o *TriggerOptions) tailWriter() io.Writer {
+	if o.Output != "" {
+		return o.GetIOFileHandles().Err
+	}
+	return o.GetIOFileHandles().Out
+}
+
+// buildParameters returns the build parameters from the parameters file and the --param values
+func (o *TriggerOptions) buildParameters() (url.Values, error) {
+	values := url.Values{}
+	var err error
+	if o.ParamsFile != "" {
+		values, err = jenkinsutil.LoadBuildParametersFile(o.ParamsFile)
+		if err != nil {
+			return values, err
+		}
+	}
+	params, err := jenkinsutil.ParseBuildParameters(o.Params)
+	if err != nil {
+		return values, err
+	}
+	return jenkinsutil.MergeBuildParameters(values, params), nil
+}
+
 // PipelineFactory jenkins jobs factory for this repository
 type PipelineFactory func() (gojenkins.Job, error)
 
-func (o *TriggerOptions) getOrCreatePipelineFactory(jenkinsClient gojenkins.JenkinsClient, gitInfo *gits.GitRepository) PipelineFactory {
+func (o *TriggerOptions) getOrCreatePipelineFactory(jenkinsClient jenkinsutil.Client, gitInfo *gits.GitRepository) PipelineFactory {
+	if o.OrganizationFolder {
+		return func() (gojenkins.Job, error) {
+			return o.getOrganizationFolderPipeline(jenkinsClient, gitInfo)
+		}
+	}
 	if o.MultiBranchProject {
 		return func() (gojenkins.Job, error) {
 			return o.getOrCreateMultiBranchPipeline(jenkinsClient, gitInfo)
@@ -180,11 +510,13 @@ func (o *TriggerOptions) getOrCreatePipelineFactory(jenkinsClient gojenkins.Jenk
 	}
 }
 
-func (o *TriggerOptions) getOrCreateStandalonePipeline(jenkinsClient gojenkins.JenkinsClient, gitInfo *gits.GitRepository) (gojenkins.Job, error) {
+func (o *TriggerOptions) getOrCreateStandalonePipeline(jenkinsClient jenkinsutil.Client, gitInfo *gits.GitRepository) (gojenkins.Job, error) {
 	var job gojenkins.Job
 	var err error
 
 	paths := strings.Split(o.JenkinsPath, "/")
+	unlock := o.folderLocks.lock(paths[0])
+	defer unlock()
 
 	last := len(paths) - 1
 	for i, path := range paths {
@@ -194,19 +526,23 @@ func (o *TriggerOptions) getOrCreateStandalonePipeline(jenkinsClient gojenkins.J
 		jobURL := util.UrlJoin(jenkinsClient.BaseURL(), fullPath)
 
 		if i < last {
+			folderXML, xmlErr := o.folderXML(gitInfo, path, fullPath, jobURL)
+			if xmlErr != nil {
+				return job, xmlErr
+			}
+
 			// lets ensure there's a folder
 			err = helpers.Retry(3, time.Second*10, func() error {
 				if err != nil {
-					folderXML := jenkins.CreateFolderXML(jobURL, path)
 					if i == 0 {
 						err = jenkinsClient.CreateJobWithXML(folderXML, path)
-						if err != nil {
+						if err != nil && !jobExists(jenkinsClient, folderPath...) {
 							return errors.Wrapf(err, "failed to create the %s folder at %s in Jenkins", path, jobURL)
 						}
 					} else {
 						folders := strings.Join(paths[0:i], "/job/")
 						err = jenkinsClient.CreateFolderJobWithXML(folderXML, folders, path)
-						if err != nil {
+						if err != nil && !jobExists(jenkinsClient, folderPath...) {
 							return errors.Wrapf(err, "failed to create the %s folder in folders %s at %s in Jenkins", path, folders, jobURL)
 						}
 					}
@@ -225,18 +561,28 @@ func (o *TriggerOptions) getOrCreateStandalonePipeline(jenkinsClient gojenkins.J
 			gitURL := gitInfo.URL
 			log.Logger().Infof("Using git URL %s and branch %s", util.ColorInfo(gitURL), util.ColorInfo(o.Branch))
 
+			pipelineXML, xmlErr := o.pipelineXML(gitInfo, path, fullPath, jobURL)
+			if xmlErr != nil {
+				return job, xmlErr
+			}
+			if err == nil {
+				err = o.reconcilePipeline(jenkinsClient, folder, pipelineXML)
+				if err != nil {
+					return job, err
+				}
+			}
+
 			err = helpers.Retry(3, time.Second*10, func() error {
 				if err != nil {
-					pipelineXML := jenkins.CreatePipelineXML(gitURL, o.Branch, o.Jenkinsfile)
 					if i == 0 {
 						err = jenkinsClient.CreateJobWithXML(pipelineXML, path)
-						if err != nil {
+						if err != nil && !jobExists(jenkinsClient, folderPath...) {
 							return errors.Wrapf(err, "failed to create the %s pipeline at %s in Jenkins", path, jobURL)
 						}
 					} else {
 						folders := strings.Join(paths[0:i], "/job/")
 						err = jenkinsClient.CreateFolderJobWithXML(pipelineXML, folders, path)
-						if err != nil {
+						if err != nil && !jobExists(jenkinsClient, folderPath...) {
 							return errors.Wrapf(err, "failed to create the %s pipeline in folders %s at %s in Jenkins", path, folders, jobURL)
 						}
 					}
@@ -256,102 +602,129 @@ func (o *TriggerOptions) getOrCreateStandalonePipeline(jenkinsClient gojenkins.J
 	return job, err
 }
 
-func (o *TriggerOptions) getOrCreateMultiBranchPipeline(jenkinsClient gojenkins.JenkinsClient, gitInfo *gits.GitRepository) (gojenkins.Job, error) {
-	job, err := jenkinsClient.GetMultiBranchJob(gitInfo.Organisation, gitInfo.Name, o.Branch)
+func (o *TriggerOptions) getOrCreateMultiBranchPipeline(jenkinsClient jenkinsutil.Client, gitInfo *gits.GitRepository) (gojenkins.Job, error) {
+	job, err := jenkinsClient.GetMultiBranchJob(gitInfo.Organisation, gitInfo.Name, o.branchJobName())
 	if err == nil {
 		return job, err
 	}
-	job, err = jenkinsClient.GetJobByPath(gitInfo.Organisation, gitInfo.Name)
+	job, err = o.getOrCreateMultiBranchProject(jenkinsClient, gitInfo)
 	if err != nil {
 		return job, err
 	}
 	job.Url = jenkins.SwitchJenkinsBaseURL(job.Url, jenkinsClient.BaseURL())
-	log.Logger().Infof("scanning multibranch project %s", job.FullName)
-	err = jenkinsClient.Build(job, url.Values{})
-	if err != nil {
-		return job, errors.Wrapf(err, "failed to scan multibranch project %s", job.FullName)
+	return o.scanMultiBranchProject(jenkinsClient, job, gitInfo)
+}
+
+// getOrCreateMultiBranchProject returns the multi branch project for the git repository creating it and its
+// owner folder if they do not exist
+func (o *TriggerOptions) getOrCreateMultiBranchProject(jenkinsClient jenkinsutil.Client, gitInfo *gits.GitRepository) (gojenkins.Job, error) {
+	owner := gitInfo.Organisation
+	name := gitInfo.Name
+	unlock := o.folderLocks.lock(owner)
+	defer unlock()
+
+	job, err := jenkinsClient.GetJobByPath(owner, name)
+	if err == nil {
+		return job, nil
+	}
+	if !isNotFound(jenkinsClient, err) {
+		return job, errors.Wrapf(err, "failed to find multibranch project %s/%s", owner, name)
 	}
-	log.Logger().Infof("waiting for job creation of %s/%s", job.FullName, o.Branch)
-	err = gojenkins.Poll(1*time.Second, 5*time.Minute, fmt.Sprintf("poll for job %s", job.FullName), func() (bool, error) {
-		job, err = jenkinsClient.GetMultiBranchJob(gitInfo.Organisation, gitInfo.Name, o.Branch)
-		if err == nil {
-			return true, nil
+
+	_, err = jenkinsClient.GetJobByPath(owner)
+	if err != nil {
+		if !isNotFound(jenkinsClient, err) {
+			return job, errors.Wrapf(err, "failed to find folder %s", owner)
 		}
-		if is404(err) {
-			return false, nil
+		folderURL := util.UrlJoin(jenkinsClient.BaseURL(), owner)
+		folderXML, err := o.folderXML(gitInfo, owner, owner, folderURL)
+		if err != nil {
+			return job, err
 		}
-		log.Logger().Debugf("not yet available %s/%s", job.FullName, o.Branch)
-		return false, err
-	})
-	return job, err
-}
+		log.Logger().Infof("creating folder %s", util.ColorInfo(owner))
+		err = helpers.Retry(3, time.Second*10, func() error {
+			err := jenkinsClient.CreateJobWithXML(folderXML, owner)
+			if err != nil && jobExists(jenkinsClient, owner) {
+				return nil
+			}
+			return err
+		})
+		if err != nil {
+			return job, errors.Wrapf(err, "failed to create the %s folder at %s in Jenkins", owner, folderURL)
+		}
+	}
 
-func (o *TriggerOptions) cancelLastBuild(jenkins gojenkins.JenkinsClient, job gojenkins.Job, waitTime time.Duration) error {
-	build, err := jenkins.GetLastBuild(job)
+	fullName := util.UrlJoin(owner, name)
+	projectURL := util.UrlJoin(jenkinsClient.BaseURL(), fullName)
+	// the job template is a standalone pipeline so it is not used for the multi branch project
+	projectXML, err := jenkinsutil.CreateMultiBranchProjectXML(gitInfo.URL, o.GitCredentialsID, o.Jenkinsfile)
 	if err != nil {
-		return err
-	}
-	if !build.Building {
-		return nil
+		return job, err
 	}
-	log.Logger().Infof("cancelling build %s/%d", job.FullName, build.Number)
-	err = jenkins.StopBuild(job, build.Number)
-	if err != nil {
+	log.Logger().Infof("creating multibranch project %s for git URL %s", util.ColorInfo(fullName), util.ColorInfo(gitInfo.URL))
+	err = helpers.Retry(3, time.Second*10, func() error {
+		err := jenkinsClient.CreateFolderJobWithXML(projectXML, owner, name)
+		if err != nil && jobExists(jenkinsClient, owner, name) {
+			return nil
+		}
 		return err
+	})
+	if err != nil {
+		return job, errors.Wrapf(err, "failed to create the %s multibranch project in folder %s at %s in Jenkins", name, owner, projectURL)
 	}
-	err = gojenkins.Poll(1*time.Second, waitTime, fmt.Sprintf("poll for cancel of %s/%d", job.FullName, build.Number),
-		func() (bool, error) {
-			build, err = jenkins.GetLastBuild(job)
-			if err != nil {
-				return false, err
-			}
-			if build.Building {
-				return false, nil
-			}
-			return true, nil
-		})
-	return err
+	return jenkinsClient.GetJobByPath(owner, name)
 }
 
-func (o *TriggerOptions) triggerAndWaitForBuildToStart(jenkins gojenkins.JenkinsClient, job gojenkins.Job, buildStartWaitTime time.Duration) (gojenkins.Build, error) {
+func (o *TriggerOptions) triggerAndWaitForBuildToStart(jenkins jenkinsutil.Client, job gojenkins.Job, params url.Values, buildStartWaitTime time.Duration) (gojenkins.Build, error) {
 	var build gojenkins.Build
-	var err error
-	previousBuildNumber := 0
-	previousBuild, err := jenkins.GetLastBuild(job)
-	if err != nil {
-		if !is404(err) {
-			return build, errors.Wrapf(err, "error finding last build for %s due to %v", job.FullName, err)
-		}
-	} else {
-		previousBuildNumber = previousBuild.Number
+	triggered := time.Now()
+	o.Result.TriggeredTime = &triggered
+	if len(params) > 0 {
+		log.Logger().Infof("triggering %s with parameters %s", job.FullName, util.ColorInfo(params.Encode()))
 	}
-	err = jenkins.Build(job, nil)
+	queueItemURL, err := jenkins.BuildWithParameters(job, params)
 	if err != nil {
-		if !is404(err) {
-			return build, errors.Wrapf(err, "error triggering build %s due to %v", job.FullName, err)
-		}
+		return build, errors.Wrapf(err, "error triggering build %s due to %v", job.FullName, err)
 	}
-	// lets wait for a new build to start
+	log.Logger().Debugf("queued build of %s as %s", job.FullName, queueItemURL)
+
+	// lets follow our queue item until it becomes a build so we don't pick up builds triggered by others
 	fn := func() (bool, error) {
-		buildNumber := 0
-		build, err = jenkins.GetLastBuild(job)
+		item, err := jenkins.GetQueueItem(queueItemURL)
 		if err != nil {
-			if !is404(err) {
-				return false, errors.Wrapf(err, "error finding last build for %s due to %v", job.FullName, err)
-			}
-		} else {
-			buildNumber = build.Number
+			return false, err
+		}
+		o.Result.QueueID = item.ID
+		if item.Cancelled {
+			return false, errors.Errorf("the queued build of %s was cancelled", job.FullName)
+		}
+		if item.Executable == nil || item.Executable.Number == 0 {
+			log.Logger().Debugf("waiting for queued build of %s: %s", job.FullName, item.Why)
+			return false, nil
 		}
-		if previousBuildNumber != buildNumber {
-			log.Logger().Infof("triggered job %s build #%d\n", job.FullName, buildNumber)
-			return true, nil
+		build, err = jenkins.GetBuild(job, item.Executable.Number)
+		if err != nil {
+			return false, errors.Wrapf(err, "error finding build %s #%d due to %v", job.FullName, item.Executable.Number, err)
 		}
-		return false, nil
+		log.Logger().Infof("triggered job %s build #%d\n", job.FullName, build.Number)
+		return true, nil
 	}
-	err = gojenkins.Poll(1*time.Second, buildStartWaitTime, fmt.Sprintf("build to start for for %s", job.FullName), fn)
+	err = helpers.Poll(1*time.Second, buildStartWaitTime, fmt.Sprintf("the build of %s to start", job.FullName), fn)
 	return build, err
 }
 
+// jobExists returns true if the job exists such as when it was created by another trigger after it was looked up
+func jobExists(jenkinsClient jenkinsutil.Client, paths ...string) bool {
+	_, err := jenkinsClient.GetJobByPath(paths...)
+	return err == nil
+}
+
+// isNotFound returns true if the error indicates the job does not exist
+func isNotFound(jenkinsClient gojenkins.JenkinsClient, err error) bool {
+	err = errors.Cause(err)
+	return jenkinsClient.IsErrNotFound(err) || is404(err)
+}
+
 func is404(err error) bool {
 	text := fmt.Sprintf("%s", err)
 	return strings.HasPrefix(text, "404 ")
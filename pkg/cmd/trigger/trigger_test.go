@@ -1,14 +1,25 @@
 package trigger_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jenkins-x-labs/trigger-pipeline/pkg/cmd/trigger"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/common"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
 	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil/fake"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/jenkins"
+	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/magiconair/properties/assert"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -31,3 +42,833 @@ func TestTrigger(t *testing.T) {
 	br := jenkinsClient.BuildRequests[0]
 	t.Logf("got build request fullName: %s name: %s\n", br.Job.FullName, br.Job.Name)
 }
+
+func TestTriggerWithParameters(t *testing.T) {
+	_, o := trigger.NewCmdTrigger()
+
+	jenkinsClient := &fake.FakeClient{}
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.Params = []string{"VERSION=1.2.3", "TARGET=linux", "TARGET=darwin"}
+	gitInfo := &gits.GitRepository{
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+	o.JenkinsPath = fmt.Sprintf("%s/%s/%s", gitInfo.Organisation, gitInfo.Name, o.Branch)
+
+	err := o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.NoError(t, err, "should not have failed")
+	assert.Equal(t, 1, len(jenkinsClient.BuildRequests), "should have a single build request")
+	br := jenkinsClient.BuildRequests[0]
+	assert.Equal(t, "1.2.3", br.Values.Get("VERSION"), "VERSION parameter")
+	assert.Equal(t, []string{"linux", "darwin"}, br.Values["TARGET"], "TARGET parameter")
+}
+
+func TestTriggerOutputJSON(t *testing.T) {
+	_, o := trigger.NewCmdTrigger()
+
+	tmpDir, err := ioutil.TempDir("", "test-trigger-output-")
+	require.NoError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(tmpDir)
+	out, err := os.Create(filepath.Join(tmpDir, "out.json"))
+	require.NoError(t, err, "failed to create output file")
+	defer out.Close()
+	o.IOFileHandles = &util.IOFileHandles{Out: out, Err: os.Stderr, In: os.Stdin}
+
+	jenkinsClient := &fake.FakeClient{BaseURLValue: "https://jenkins.acme.com"}
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.Output = "json"
+	gitInfo := &gits.GitRepository{
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+	o.JenkinsPath = fmt.Sprintf("%s/%s/%s", gitInfo.Organisation, gitInfo.Name, o.Branch)
+
+	err = o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.NoError(t, err, "should not have failed")
+
+	data, err := ioutil.ReadFile(out.Name())
+	require.NoError(t, err, "failed to read output")
+	result := trigger.TriggerResult{}
+	err = json.Unmarshal(data, &result)
+	require.NoError(t, err, "failed to parse output %s", string(data))
+
+	assert.Equal(t, result.Job, jenkinsClient.BuildRequests[0].Job.FullName, "job")
+	assert.Equal(t, 1, result.QueueID, "queueId")
+	assert.Equal(t, true, result.BuildNumber > 0, "buildNumber")
+	assert.Equal(t, true, result.TriggeredTime != nil, "triggeredTime")
+}
+
+func TestTriggerWaitExitCodes(t *testing.T) {
+	testCases := []struct {
+		result     string
+		unstableOK bool
+		exitCode   int
+	}{
+		{"SUCCESS", false, common.ExitSuccess},
+		{"FAILURE", false, common.ExitFailure},
+		{"UNSTABLE", false, common.ExitUnstable},
+		{"UNSTABLE", true, common.ExitSuccess},
+		{"ABORTED", false, common.ExitAborted},
+	}
+	for _, tc := range testCases {
+		_, o := trigger.NewCmdTrigger()
+
+		jenkinsClient := &fake.FakeClient{BuildResult: tc.result}
+		o.Branch = "master"
+		o.Dir = filepath.Join("test_data/sample")
+		o.Wait = true
+		o.UnstableOK = tc.unstableOK
+		gitInfo := &gits.GitRepository{
+			Host:         "https://github.com",
+			Organisation: "myowner",
+			Name:         "myrepo",
+		}
+		o.JenkinsPath = fmt.Sprintf("%s/%s/%s", gitInfo.Organisation, gitInfo.Name, o.Branch)
+
+		err := o.TriggerPipeline(jenkinsClient, gitInfo)
+		if tc.exitCode == common.ExitSuccess {
+			require.NoError(t, err, "should not have failed for result %s", tc.result)
+		} else {
+			exitErr := &common.ExitCodeError{}
+			require.True(t, errors.As(err, &exitErr), "should have an exit code error for result %s but got %v", tc.result, err)
+			assert.Equal(t, exitErr.Code, tc.exitCode, "exit code for result %s", tc.result)
+		}
+		assert.Equal(t, o.Result.Result, tc.result, "result")
+	}
+}
+
+func TestTriggerWaitAbortOnTimeout(t *testing.T) {
+	_, o := trigger.NewCmdTrigger()
+
+	jenkinsClient := &fake.FakeClient{}
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.Wait = true
+	o.PollInterval = 10 * time.Millisecond
+	o.BuildTimeout = 100 * time.Millisecond
+	o.AbortOnTimeout = true
+	gitInfo := &gits.GitRepository{
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+	o.JenkinsPath = fmt.Sprintf("%s/%s/%s", gitInfo.Organisation, gitInfo.Name, o.Branch)
+
+	err := o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.Error(t, err, "should have timed out")
+	assert.Equal(t, common.IsTimeout(err), true, "should be a timeout error")
+	assert.Equal(t, len(jenkinsClient.StoppedBuilds), 1, "should have stopped the build")
+	assert.Equal(t, jenkinsClient.StoppedBuilds[0], o.Result.BuildNumber, "stopped build number")
+}
+
+func TestTriggerReconcile(t *testing.T) {
+	testCases := []struct {
+		reconcile string
+		updated   bool
+		fail      bool
+	}{
+		{trigger.ReconcileWarn, false, false},
+		{trigger.ReconcileUpdate, true, false},
+		{trigger.ReconcileFail, false, true},
+	}
+	for _, tc := range testCases {
+		jenkinsClient := &fake.FakeClient{}
+		gitInfo := &gits.GitRepository{
+			URL:          "https://github.com/myowner/myrepo.git",
+			Host:         "https://github.com",
+			Organisation: "myowner",
+			Name:         "myrepo",
+		}
+
+		_, o := trigger.NewCmdTrigger()
+		o.Branch = "master"
+		o.Dir = filepath.Join("test_data/sample")
+		o.JenkinsPath = fmt.Sprintf("%s/%s/%s", gitInfo.Organisation, gitInfo.Name, o.Branch)
+		err := o.TriggerPipeline(jenkinsClient, gitInfo)
+		require.NoError(t, err, "should not have failed creating the pipeline")
+
+		// lets trigger again using a different Jenkinsfile
+		_, o = trigger.NewCmdTrigger()
+		o.Branch = "master"
+		o.Dir = filepath.Join("test_data/sample")
+		o.JenkinsPath = fmt.Sprintf("%s/%s/%s", gitInfo.Organisation, gitInfo.Name, o.Branch)
+		o.Jenkinsfile = "Jenkinsfile.release"
+		o.Reconcile = tc.reconcile
+		err = o.TriggerPipeline(jenkinsClient, gitInfo)
+		if tc.fail {
+			require.Error(t, err, "should have failed for reconcile %s", tc.reconcile)
+		} else {
+			require.NoError(t, err, "should not have failed for reconcile %s", tc.reconcile)
+		}
+
+		if tc.updated {
+			require.Equal(t, 1, len(jenkinsClient.UpdatedJobs), "should have updated the pipeline for reconcile %s", tc.reconcile)
+			config, err := jenkinsutil.ParsePipelineConfig(jenkinsClient.UpdatedJobs[0].JobItemXml)
+			require.NoError(t, err, "failed to parse updated pipeline XML")
+			assert.Equal(t, config.ScriptPath, "Jenkinsfile.release", "updated Jenkinsfile")
+		} else {
+			assert.Equal(t, len(jenkinsClient.UpdatedJobs), 0, "should not have updated the pipeline for reconcile %s", tc.reconcile)
+		}
+	}
+}
+
+func TestTriggerReconcileFreestyleJob(t *testing.T) {
+	testCases := []struct {
+		reconcile string
+		fail      bool
+	}{
+		{trigger.ReconcileWarn, false},
+		{trigger.ReconcileUpdate, true},
+		{trigger.ReconcileFail, true},
+	}
+	for _, tc := range testCases {
+		jenkinsClient := &fake.FakeClient{
+			BaseURLValue: "https://jenkins.acme.com",
+			BuildResult:  "SUCCESS",
+			FolderXMLJobs: []fake.FolderXMLJob{
+				{
+					Folder:     "myowner/job/myrepo",
+					JobName:    "master",
+					JobItemXml: "<?xml version='1.1' encoding='UTF-8'?>\n<project><builders/></project>",
+				},
+			},
+		}
+		gitInfo := &gits.GitRepository{
+			URL:          "https://github.com/myowner/myrepo.git",
+			Host:         "https://github.com",
+			Organisation: "myowner",
+			Name:         "myrepo",
+		}
+
+		_, o := trigger.NewCmdTrigger()
+		o.Branch = "master"
+		o.Dir = filepath.Join("test_data/sample")
+		o.JenkinsPath = "myowner/myrepo/master"
+		o.Reconcile = tc.reconcile
+		err := o.TriggerPipeline(jenkinsClient, gitInfo)
+		if tc.fail {
+			require.Error(t, err, "should have failed for reconcile %s", tc.reconcile)
+		} else {
+			require.NoError(t, err, "should not have failed for reconcile %s", tc.reconcile)
+			assert.Equal(t, len(jenkinsClient.BuildRequests), 1, "should have triggered the job for reconcile "+tc.reconcile)
+		}
+		assert.Equal(t, len(jenkinsClient.UpdatedJobs), 0, "should not have updated the job for reconcile "+tc.reconcile)
+	}
+}
+
+func TestTriggerDryRun(t *testing.T) {
+	_, o := trigger.NewCmdTrigger()
+
+	tmpDir, err := ioutil.TempDir("", "test-trigger-dry-run-")
+	require.NoError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(tmpDir)
+	outFile := filepath.Join(tmpDir, "plan.json")
+	out, err := os.Create(outFile)
+	require.NoError(t, err, "failed to create output file")
+	defer out.Close()
+	o.IOFileHandles = &util.IOFileHandles{Out: out, Err: os.Stderr, In: os.Stdin}
+
+	jenkinsClient := &fake.FakeClient{BaseURLValue: "https://jenkins.acme.com"}
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.Output = "json"
+	o.DryRun = true
+	o.Params = []string{"VERSION=1.2.3"}
+	gitInfo := &gits.GitRepository{
+		URL:          "https://github.com/myowner/myrepo.git",
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+	o.JenkinsPath = fmt.Sprintf("%s/%s/%s", gitInfo.Organisation, gitInfo.Name, o.Branch)
+
+	err = o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.NoError(t, err, "should not have failed")
+	assert.Equal(t, len(jenkinsClient.XMLJobs), 0, "should not have created any jobs")
+	assert.Equal(t, len(jenkinsClient.FolderXMLJobs), 0, "should not have created any folder jobs")
+	assert.Equal(t, len(jenkinsClient.BuildRequests), 0, "should not have triggered a build")
+
+	data, err := ioutil.ReadFile(outFile)
+	require.NoError(t, err, "failed to read output file")
+	plan := &trigger.TriggerPlan{}
+	require.NoError(t, json.Unmarshal(data, plan), "failed to parse plan %s", string(data))
+
+	assert.Equal(t, plan.Job, "myowner/myrepo/master", "job")
+	require.Equal(t, 4, len(plan.Actions), "actions")
+	for i, name := range []string{"myowner", "myowner/myrepo", "myowner/myrepo/master"} {
+		assert.Equal(t, plan.Actions[i].Action, jenkinsutil.DryRunCreate, fmt.Sprintf("action %d", i))
+		assert.Equal(t, plan.Actions[i].Job, name, fmt.Sprintf("job of action %d", i))
+		assert.Equal(t, plan.Actions[i].IsFolder(), i < 2, fmt.Sprintf("folder action %d", i))
+	}
+	assert.Equal(t, plan.Actions[3].Action, jenkinsutil.DryRunBuild, "build action")
+	assert.Equal(t, plan.Actions[3].Parameters.Get("VERSION"), "1.2.3", "build parameter")
+}
+
+func TestTriggerDryRunMultiBranchProject(t *testing.T) {
+	_, o := trigger.NewCmdTrigger()
+
+	tmpDir, err := ioutil.TempDir("", "test-trigger-dry-run-multibranch-")
+	require.NoError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(tmpDir)
+	outFile := filepath.Join(tmpDir, "plan.json")
+	out, err := os.Create(outFile)
+	require.NoError(t, err, "failed to create output file")
+	defer out.Close()
+	o.IOFileHandles = &util.IOFileHandles{Out: out, Err: os.Stderr, In: os.Stdin}
+
+	jenkinsClient := &fake.FakeClient{BaseURLValue: "https://jenkins.acme.com"}
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.Output = "json"
+	o.DryRun = true
+	o.MultiBranchProject = true
+	o.ScanTimeout = time.Minute
+	gitInfo := &gits.GitRepository{
+		URL:          "https://github.com/myowner/myrepo.git",
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+
+	start := time.Now()
+	err = o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.NoError(t, err, "should not have failed")
+	assert.Equal(t, time.Since(start) < time.Second, true, "should not have waited for the scan")
+	assert.Equal(t, len(jenkinsClient.BuildRequests), 0, "should not have scanned or triggered anything")
+
+	data, err := ioutil.ReadFile(outFile)
+	require.NoError(t, err, "failed to read output file")
+	plan := &trigger.TriggerPlan{}
+	require.NoError(t, json.Unmarshal(data, plan), "failed to parse plan %s", string(data))
+
+	require.Equal(t, 4, len(plan.Actions), "actions")
+	assert.Equal(t, plan.Actions[0].Action, jenkinsutil.DryRunCreate, "owner folder action")
+	assert.Equal(t, plan.Actions[0].Job, "myowner", "owner folder")
+	assert.Equal(t, plan.Actions[1].Action, jenkinsutil.DryRunCreate, "multibranch project action")
+	assert.Equal(t, plan.Actions[1].Class, jenkinsutil.MultiBranchProjectClass, "multibranch project class")
+	assert.Equal(t, plan.Actions[2].Action, jenkinsutil.DryRunScan, "scan action")
+	assert.Equal(t, plan.Actions[2].Job, "myowner/myrepo", "scanned project")
+	assert.Equal(t, plan.Actions[3].Action, jenkinsutil.DryRunBuild, "build action")
+	assert.Equal(t, plan.Actions[3].Job, "myowner/myrepo/master", "triggered branch")
+}
+
+func TestTriggerWithTemplates(t *testing.T) {
+	_, o := trigger.NewCmdTrigger()
+
+	jenkinsClient := &fake.FakeClient{}
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.JobTemplate = filepath.Join("test_data", "templates", "job.xml.tmpl")
+	o.FolderTemplate = filepath.Join("test_data", "templates", "folder.xml.tmpl")
+	gitInfo := &gits.GitRepository{
+		URL:          "https://github.com/myowner/myrepo.git",
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+	o.JenkinsPath = fmt.Sprintf("%s/%s/%s", gitInfo.Organisation, gitInfo.Name, o.Branch)
+
+	err := o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.NoError(t, err, "should not have failed")
+
+	require.Equal(t, 1, len(jenkinsClient.XMLJobs), "should have created the owner folder")
+	assert.Equal(t, strings.Contains(jenkinsClient.XMLJobs[0].JobItemXml, "<description>folder for myowner/myrepo</description>"), true, "folder XML from template")
+
+	require.Equal(t, 2, len(jenkinsClient.FolderXMLJobs), "should have created the repo folder and pipeline")
+	pipelineXML := jenkinsClient.FolderXMLJobs[1].JobItemXml
+	assert.Equal(t, strings.Contains(pipelineXML, "DisableConcurrentBuildsJobProperty"), true, "pipeline XML from template")
+	config, err := jenkinsutil.ParsePipelineConfig(pipelineXML)
+	require.NoError(t, err, "failed to parse pipeline XML")
+	assert.Equal(t, config.GitURL, gitInfo.URL, "git URL")
+	assert.Equal(t, config.Branch, "*/master", "branch")
+}
+
+func TestTriggerWithGitCredentials(t *testing.T) {
+	_, o := trigger.NewCmdTrigger()
+
+	jenkinsClient := &fake.FakeClient{}
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.GitCredentialsID = "github-token"
+	gitInfo := &gits.GitRepository{
+		URL:          "https://github.com/myowner/myrepo.git",
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+	o.JenkinsPath = fmt.Sprintf("%s/%s/%s", gitInfo.Organisation, gitInfo.Name, o.Branch)
+
+	err := o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.NoError(t, err, "should not have failed")
+
+	require.Equal(t, 2, len(jenkinsClient.FolderXMLJobs), "should have created the repo folder and pipeline")
+	config, err := jenkinsutil.ParsePipelineConfig(jenkinsClient.FolderXMLJobs[1].JobItemXml)
+	require.NoError(t, err, "failed to parse pipeline XML")
+	assert.Equal(t, config.CredentialsID, "github-token", "git credentials")
+}
+
+func TestTriggerCancel(t *testing.T) {
+	jobURL := "https://jenkins.acme.com/job/myowner/job/myrepo/job/master"
+	testCases := []struct {
+		name        string
+		buildNumber int
+		allRunning  bool
+		stopped     []int
+		queueItems  []int
+	}{
+		{"build number", 3, false, []int{3}, nil},
+		{"build number not running", 2, false, nil, nil},
+		{"all running", 0, true, []int{1, 3}, []int{100}},
+	}
+	for _, tc := range testCases {
+		jenkinsClient := &fake.FakeClient{
+			BaseURLValue: "https://jenkins.acme.com",
+			Builds: []gojenkins.Build{
+				{Number: 1, Building: true},
+				{Number: 2, Result: "SUCCESS"},
+				{Number: 3, Building: true},
+			},
+			QueueItems: []jenkinsutil.QueueItem{
+				{ID: 100, Task: &jenkinsutil.QueueTask{Name: "master", URL: jobURL}},
+				{ID: 101, Task: &jenkinsutil.QueueTask{Name: "master", URL: "https://jenkins.acme.com/job/other"}},
+			},
+		}
+		gitInfo := &gits.GitRepository{
+			URL:          "https://github.com/myowner/myrepo.git",
+			Host:         "https://github.com",
+			Organisation: "myowner",
+			Name:         "myrepo",
+		}
+		jenkinsClient.FolderXMLJobs = []fake.FolderXMLJob{
+			{
+				JobItemXml: jenkins.CreatePipelineXML(gitInfo.URL, "master", "Jenkinsfile"),
+				Folder:     "myowner/job/myrepo",
+				JobName:    "master",
+			},
+		}
+
+		_, o := trigger.NewCmdTrigger()
+		o.Branch = "master"
+		o.Dir = filepath.Join("test_data/sample")
+		o.JenkinsPath = fmt.Sprintf("%s/%s/%s", gitInfo.Organisation, gitInfo.Name, o.Branch)
+		o.Cancel = true
+		o.BuildNumber = tc.buildNumber
+		o.AllRunning = tc.allRunning
+
+		err := o.TriggerPipeline(jenkinsClient, gitInfo)
+		require.NoError(t, err, "should not have failed for %s", tc.name)
+		assert.Equal(t, jenkinsClient.StoppedBuilds, tc.stopped, "stopped builds for "+tc.name)
+		assert.Equal(t, jenkinsClient.CancelledQueueItems, tc.queueItems, "cancelled queue items for "+tc.name)
+		require.Equal(t, len(o.Result.StoppedBuilds), len(tc.stopped), "reported stopped builds for "+tc.name)
+		for i, b := range o.Result.StoppedBuilds {
+			assert.Equal(t, b.Number, tc.stopped[i], "reported stopped build for "+tc.name)
+			assert.Equal(t, b.Result, "ABORTED", "final result for "+tc.name)
+		}
+	}
+}
+
+func TestTriggerCancelLastBuildRemovesQueuedBuilds(t *testing.T) {
+	jobURL := "https://jenkins.acme.com/job/myowner/job/myrepo/job/master"
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		QueueItems: []jenkinsutil.QueueItem{
+			{ID: 100, Task: &jenkinsutil.QueueTask{Name: "master", URL: jobURL}},
+			{ID: 101, Task: &jenkinsutil.QueueTask{Name: "master", URL: "https://jenkins.acme.com/job/other"}},
+		},
+	}
+	gitInfo := &gits.GitRepository{
+		URL:          "https://github.com/myowner/myrepo.git",
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+	jenkinsClient.FolderXMLJobs = []fake.FolderXMLJob{
+		{
+			JobItemXml: jenkins.CreatePipelineXML(gitInfo.URL, "master", "Jenkinsfile"),
+			Folder:     "myowner/job/myrepo",
+			JobName:    "master",
+		},
+	}
+
+	_, o := trigger.NewCmdTrigger()
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.JenkinsPath = fmt.Sprintf("%s/%s/%s", gitInfo.Organisation, gitInfo.Name, o.Branch)
+	o.Cancel = true
+
+	err := o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.NoError(t, err, "should not have failed")
+	assert.Equal(t, len(jenkinsClient.StoppedBuilds), 1, "should have stopped the last build")
+	assert.Equal(t, jenkinsClient.CancelledQueueItems, []int{100}, "cancelled queue items")
+	assert.Equal(t, o.Result.CancelledQueueItems, []int{100}, "reported cancelled queue items")
+}
+
+func TestTriggerSupersede(t *testing.T) {
+	jobURL := "https://jenkins.acme.com/job/myowner/job/myrepo/job/master"
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		Builds: []gojenkins.Build{
+			{Number: 1, Building: true},
+			{Number: 2, Result: "SUCCESS"},
+			{Number: 3, Building: true},
+		},
+		QueueItems: []jenkinsutil.QueueItem{
+			{ID: 100, Task: &jenkinsutil.QueueTask{Name: "master", URL: jobURL}},
+		},
+	}
+	gitInfo := &gits.GitRepository{
+		URL:          "https://github.com/myowner/myrepo.git",
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+	jenkinsClient.FolderXMLJobs = []fake.FolderXMLJob{
+		{
+			JobItemXml: jenkins.CreatePipelineXML(gitInfo.URL, "master", "Jenkinsfile"),
+			Folder:     "myowner/job/myrepo",
+			JobName:    "master",
+		},
+	}
+
+	_, o := trigger.NewCmdTrigger()
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.JenkinsPath = fmt.Sprintf("%s/%s/%s", gitInfo.Organisation, gitInfo.Name, o.Branch)
+	o.Supersede = true
+
+	err := o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.NoError(t, err, "should not have failed")
+	assert.Equal(t, jenkinsClient.StoppedBuilds, []int{1, 3}, "stopped builds")
+	assert.Equal(t, jenkinsClient.CancelledQueueItems, []int{100}, "cancelled queue items")
+	assert.Equal(t, len(jenkinsClient.BuildRequests), 1, "number of triggered builds")
+	assert.Equal(t, len(o.Result.StoppedBuilds), 2, "reported stopped builds")
+}
+
+func TestTriggerCreatesMultiBranchProject(t *testing.T) {
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		ScanBranches: []string{"master"},
+	}
+	gitInfo := &gits.GitRepository{
+		URL:          "https://github.com/myowner/myrepo.git",
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+
+	_, o := trigger.NewCmdTrigger()
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.MultiBranchProject = true
+	// the standalone pipeline template should not be used for the multi branch project
+	o.JobTemplate = filepath.Join("test_data", "templates", "job.xml.tmpl")
+
+	err := o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.NoError(t, err, "should not have failed")
+
+	require.Equal(t, 1, len(jenkinsClient.XMLJobs), "should have created the owner folder")
+	assert.Equal(t, jenkinsClient.XMLJobs[0].JobName, "myowner", "owner folder")
+
+	project := jenkinsClient.FolderXMLJobs[0]
+	assert.Equal(t, project.Folder, "myowner", "multibranch project folder")
+	assert.Equal(t, project.JobName, "myrepo", "multibranch project name")
+	assert.Equal(t, strings.Contains(project.JobItemXml, jenkinsutil.MultiBranchProjectClass), true, "multibranch project XML")
+	assert.Equal(t, strings.Contains(project.JobItemXml, "flow-definition"), false, "should not use the job template")
+	assert.Equal(t, strings.Contains(project.JobItemXml, "<remote>https://github.com/myowner/myrepo.git</remote>"), true, "git branch source")
+
+	require.Equal(t, 2, len(jenkinsClient.BuildRequests), "should have scanned the project then triggered the branch")
+	assert.Equal(t, jenkinsClient.BuildRequests[0].Job.Url, "https://jenkins.acme.com/job/myowner/job/myrepo", "scanned project")
+	assert.Equal(t, jenkinsClient.BuildRequests[1].Job.Url, "https://jenkins.acme.com/job/myowner/job/myrepo/job/master", "triggered branch")
+}
+
+func TestTriggerMultiBranchScanWithoutBranch(t *testing.T) {
+	projectXML, err := jenkinsutil.CreateMultiBranchProjectXML("https://github.com/myowner/myrepo.git", "", "Jenkinsfile")
+	require.NoError(t, err, "failed to create multibranch project XML")
+
+	scanLog := []string{"Started", "Checking branches..."}
+	for i := 0; i < 30; i++ {
+		scanLog = append(scanLog, fmt.Sprintf("Checking branch feature-%d", i))
+	}
+	scanLog = append(scanLog, "      'Jenkinsfile' not found", "    Does not meet criteria", "Finished: SUCCESS")
+
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		FolderXMLJobs: []fake.FolderXMLJob{
+			{
+				JobItemXml: projectXML,
+				Folder:     "myowner",
+				JobName:    "myrepo",
+			},
+		},
+		IndexingLog: strings.Join(scanLog, "\n") + "\n",
+	}
+	gitInfo := &gits.GitRepository{
+		URL:          "https://github.com/myowner/myrepo.git",
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+
+	_, o := trigger.NewCmdTrigger()
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.MultiBranchProject = true
+	o.ScanTimeout = time.Minute
+
+	start := time.Now()
+	err = o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.Error(t, err, "should have failed as the scan does not create the branch")
+	assert.Equal(t, time.Since(start) < o.ScanTimeout, true, "should have failed before the scan timeout")
+	assert.Equal(t, strings.Contains(err.Error(), "'Jenkinsfile' not found"), true, "error should contain the end of the scan log")
+	assert.Equal(t, strings.Contains(err.Error(), "Checking branches..."), false, "error should only contain the end of the scan log")
+	assert.Equal(t, 1, len(jenkinsClient.BuildRequests), "should only have scanned the project")
+}
+
+func TestTriggerOrganizationFolder(t *testing.T) {
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		Jobs: []gojenkins.Job{
+			{
+				Name:     "myowner",
+				FullName: "myowner",
+				Class:    jenkinsutil.OrganizationFolderClass,
+				Url:      "https://jenkins.acme.com/job/myowner",
+			},
+		},
+		OrganizationScanJobs: []gojenkins.Job{
+			{
+				Name:     "myrepo",
+				FullName: "myowner/myrepo",
+				Class:    jenkinsutil.MultiBranchProjectClass,
+				Jobs: []gojenkins.Job{
+					{Name: "master", FullName: "myowner/myrepo/master"},
+				},
+			},
+		},
+	}
+	gitInfo := &gits.GitRepository{
+		URL:          "https://github.com/myowner/myrepo.git",
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+
+	_, o := trigger.NewCmdTrigger()
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.OrganizationFolder = true
+
+	err := o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.NoError(t, err, "should not have failed")
+
+	require.Equal(t, 2, len(jenkinsClient.BuildRequests), "should have scanned the organization folder then triggered the branch")
+	assert.Equal(t, jenkinsClient.BuildRequests[0].Job.FullName, "myowner", "scanned organization folder")
+	assert.Equal(t, jenkinsClient.BuildRequests[1].Job.FullName, "myowner/myrepo/master", "triggered branch")
+	assert.Equal(t, 0, len(jenkinsClient.XMLJobs)+len(jenkinsClient.FolderXMLJobs), "should not have created any jobs")
+}
+
+func TestTriggerPullRequest(t *testing.T) {
+	projectXML, err := jenkinsutil.CreateMultiBranchProjectXML("https://github.com/myowner/myrepo.git", "", "Jenkinsfile")
+	require.NoError(t, err, "failed to create multibranch project XML")
+
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		FolderXMLJobs: []fake.FolderXMLJob{
+			{
+				JobItemXml: projectXML,
+				Folder:     "myowner",
+				JobName:    "myrepo",
+			},
+		},
+		ScanBranches: []string{"master", "PR-123"},
+	}
+	gitInfo := &gits.GitRepository{
+		URL:          "https://github.com/myowner/myrepo.git",
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+
+	_, o := trigger.NewCmdTrigger()
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.MultiBranchProject = true
+	o.PullRequest = 123
+
+	err = o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.NoError(t, err, "should not have failed")
+
+	require.Equal(t, 2, len(jenkinsClient.BuildRequests), "should have scanned the project then triggered the pull request")
+	assert.Equal(t, jenkinsClient.BuildRequests[1].Job.Url, "https://jenkins.acme.com/job/myowner/job/myrepo/job/PR-123", "triggered pull request job")
+}
+
+func TestTriggerPullRequestNotDiscovered(t *testing.T) {
+	projectXML, err := jenkinsutil.CreateMultiBranchProjectXML("https://github.com/myowner/myrepo.git", "", "Jenkinsfile")
+	require.NoError(t, err, "failed to create multibranch project XML")
+
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		FolderXMLJobs: []fake.FolderXMLJob{
+			{
+				JobItemXml: projectXML,
+				Folder:     "myowner",
+				JobName:    "myrepo",
+			},
+		},
+		ScanBranches: []string{"master"},
+		IndexingLog:  "Checking branches...\nFinished: SUCCESS\n",
+	}
+	gitInfo := &gits.GitRepository{
+		URL:          "https://github.com/myowner/myrepo.git",
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+
+	_, o := trigger.NewCmdTrigger()
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.MultiBranchProject = true
+	o.PullRequest = 123
+	o.ScanTimeout = time.Minute
+
+	err = o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.Error(t, err, "should have failed as the scan does not discover pull requests")
+	assert.Equal(t, strings.Contains(err.Error(), "only discover branches"), true, "error should explain that pull requests are not discovered")
+	assert.Equal(t, 1, len(jenkinsClient.BuildRequests), "should only have scanned the project")
+}
+
+func TestTriggerTailLogFile(t *testing.T) {
+	consoleLog := "Started by user admin\n[Pipeline] Start of Pipeline\n[Pipeline] { (Build)\n+ make build\nWARNING: deprecated flag\nFinished: SUCCESS"
+	testCases := []struct {
+		name    string
+		tailErr error
+	}{
+		{"completed", nil},
+		{"timed out", errors.New("timed out tailing the log")},
+	}
+	for _, tc := range testCases {
+		tmpDir, err := ioutil.TempDir("", "test-trigger-log-file-")
+		require.NoError(t, err, "failed to create temp dir")
+		defer os.RemoveAll(tmpDir)
+		out, err := os.Create(filepath.Join(tmpDir, "out.txt"))
+		require.NoError(t, err, "failed to create output file")
+		defer out.Close()
+
+		_, o := trigger.NewCmdTrigger()
+		o.IOFileHandles = &util.IOFileHandles{Out: out, Err: os.Stderr, In: os.Stdin}
+		o.Branch = "master"
+		o.Dir = filepath.Join("test_data/sample")
+		o.JenkinsPath = "myowner/myrepo/master"
+		o.Tail = true
+		o.LogFile = filepath.Join(tmpDir, "logs", "build.log")
+		o.LogStream = trigger.LogStreamCondensed
+		gitInfo := &gits.GitRepository{
+			Host:         "https://github.com",
+			Organisation: "myowner",
+			Name:         "myrepo",
+		}
+		jenkinsClient := &fake.FakeClient{
+			BaseURLValue: "https://jenkins.acme.com",
+			BuildResult:  "SUCCESS",
+			ConsoleLog:   consoleLog,
+			TailLogError: tc.tailErr,
+		}
+
+		err = o.TriggerPipeline(jenkinsClient, gitInfo)
+		if tc.tailErr != nil {
+			require.Error(t, err, "should have failed for %s", tc.name)
+		} else {
+			require.NoError(t, err, "should not have failed for %s", tc.name)
+		}
+
+		data, err := ioutil.ReadFile(o.LogFile)
+		require.NoError(t, err, "failed to read log file for %s", tc.name)
+		assert.Equal(t, string(data), consoleLog, "log file for "+tc.name)
+
+		data, err = ioutil.ReadFile(out.Name())
+		require.NoError(t, err, "failed to read output for %s", tc.name)
+		assert.Equal(t, string(data), "[Pipeline] { (Build)\nWARNING: deprecated flag\nFinished: SUCCESS\n", "condensed output for "+tc.name)
+	}
+}
+
+func TestTriggerTailLogFormat(t *testing.T) {
+	consoleLog := "Started by user admin\n\x1b[8mha:////4AAAAA==\x1b[0m\x1b[32m+ make build\x1b[0m\nFinished: SUCCESS\n"
+	tmpDir, err := ioutil.TempDir("", "test-trigger-log-format-")
+	require.NoError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(tmpDir)
+	out, err := os.Create(filepath.Join(tmpDir, "out.txt"))
+	require.NoError(t, err, "failed to create output file")
+	defer out.Close()
+
+	_, o := trigger.NewCmdTrigger()
+	o.IOFileHandles = &util.IOFileHandles{Out: out, Err: os.Stderr, In: os.Stdin}
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.JenkinsPath = "myowner/myrepo/master"
+	o.Tail = true
+	o.LogPrefix = true
+	o.StripANSI = true
+	gitInfo := &gits.GitRepository{
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		BuildResult:  "SUCCESS",
+		ConsoleLog:   consoleLog,
+	}
+
+	err = o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.NoError(t, err, "should not have failed")
+
+	data, err := ioutil.ReadFile(out.Name())
+	require.NoError(t, err, "failed to read output")
+	prefix := fmt.Sprintf("[%s#%d] ", o.Result.Job, o.Result.BuildNumber)
+	assert.Equal(t, string(data), prefix+"Started by user admin\n"+prefix+"+ make build\n"+prefix+"Finished: SUCCESS\n", "formatted output")
+}
+
+func TestTriggerTailTimeoutWritesResult(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test-trigger-tail-timeout-")
+	require.NoError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(tmpDir)
+	out, err := os.Create(filepath.Join(tmpDir, "result.json"))
+	require.NoError(t, err, "failed to create output file")
+	defer out.Close()
+
+	_, o := trigger.NewCmdTrigger()
+	o.IOFileHandles = &util.IOFileHandles{Out: out, Err: os.Stderr, In: os.Stdin}
+	o.Branch = "master"
+	o.Dir = filepath.Join("test_data/sample")
+	o.JenkinsPath = "myowner/myrepo/master"
+	o.Tail = true
+	o.Output = "json"
+	o.BuildTimeout = 50 * time.Millisecond
+	gitInfo := &gits.GitRepository{
+		Host:         "https://github.com",
+		Organisation: "myowner",
+		Name:         "myrepo",
+	}
+	jenkinsClient := &fake.FakeClient{
+		BaseURLValue: "https://jenkins.acme.com",
+		ConsoleLog:   "+ make build\n",
+	}
+
+	err = o.TriggerPipeline(jenkinsClient, gitInfo)
+	require.Error(t, err, "should have timed out")
+	assert.Equal(t, common.IsTimeout(err), true, "should be a timeout error")
+
+	data, err := ioutil.ReadFile(out.Name())
+	require.NoError(t, err, "failed to read output")
+	result := &trigger.TriggerResult{}
+	err = json.Unmarshal(data, result)
+	require.NoError(t, err, "failed to parse result %s", string(data))
+	assert.Equal(t, result.BuildNumber, o.Result.BuildNumber, "build number")
+	assert.Equal(t, result.BuildNumber > 0, true, "should have a build number")
+	assert.Equal(t, result.TimedOut, true, "timed out")
+}
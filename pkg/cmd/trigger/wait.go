@@ -0,0 +1,65 @@
+package trigger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/helpers"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+// waitForBuildToComplete polls the build until it has completed periodically logging its progress or the
+// stages which start and complete if --stages is enabled
+func (o *TriggerOptions) waitForBuildToComplete(jenkins jenkinsutil.Client, job gojenkins.Job, build gojenkins.Build, timeout time.Duration) (gojenkins.Build, error) {
+	log.Logger().Infof("waiting for %s #%d to complete", job.FullName, build.Number)
+	var lastProgress time.Time
+	tracker := &stageTracker{}
+	fn := func() (bool, error) {
+		b, err := jenkins.GetBuild(job, build.Number)
+		if err != nil {
+			return false, err
+		}
+		build = b
+		if o.Stages {
+			o.logStageTransitions(jenkins, job, &build, tracker)
+		}
+		if !build.Building {
+			return true, nil
+		}
+		if !o.Stages && time.Since(lastProgress) >= o.ProgressInterval {
+			lastProgress = time.Now()
+			o.logProgress(jenkins, job, &build)
+		}
+		return false, nil
+	}
+	err := helpers.Poll(o.PollInterval, timeout, fmt.Sprintf("build %s #%d to complete", job.FullName, build.Number), fn)
+	return build, err
+}
+
+// logProgress logs the elapsed time, current stage and estimated duration of a running build
+func (o *TriggerOptions) logProgress(jenkins jenkinsutil.Client, job gojenkins.Job, build *gojenkins.Build) {
+	message := fmt.Sprintf("%s #%d running", job.FullName, build.Number)
+	if build.Timestamp > 0 {
+		started := time.Unix(0, int64(build.Timestamp)*int64(time.Millisecond))
+		message += fmt.Sprintf(" for %s", util.ColorInfo(time.Since(started).Round(time.Second).String()))
+	}
+
+	run, err := jenkins.GetPipelineRun(build.Url)
+	if err != nil {
+		log.Logger().Debugf("failed to get the stages of %s #%d: %s", job.FullName, build.Number, err.Error())
+	} else if stage := run.CurrentStage(); stage != nil {
+		message += fmt.Sprintf(" stage %s", util.ColorInfo(stage.Name))
+	}
+
+	details, err := jenkins.GetBuildDetails(build.Url)
+	if err != nil {
+		log.Logger().Debugf("failed to get the details of %s #%d: %s", job.FullName, build.Number, err.Error())
+	} else if details.EstimatedDuration > 0 {
+		estimated := time.Duration(details.EstimatedDuration) * time.Millisecond
+		message += fmt.Sprintf(" estimated duration %s", estimated.Round(time.Second).String())
+	}
+	log.Logger().Info(message)
+}
@@ -0,0 +1,112 @@
+package common
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ExitSuccess the build succeeded
+	ExitSuccess = 0
+
+	// ExitError a tool, configuration or connection error
+	ExitError = 1
+
+	// ExitFailure the build failed
+	ExitFailure = 2
+
+	// ExitUnstable the build was unstable
+	ExitUnstable = 3
+
+	// ExitAborted the build was aborted
+	ExitAborted = 4
+
+	// ExitNotBuilt the build was not built
+	ExitNotBuilt = 5
+
+	// ExitTimeout timed out waiting for Jenkins
+	ExitTimeout = 6
+)
+
+// ExitCodeError an error which exits the process with a specific exit code
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+// NewExitCodeError creates a new error with the given exit code
+func NewExitCodeError(code int, err error) *ExitCodeError {
+	return &ExitCodeError{Code: code, Err: err}
+}
+
+// Error implements error
+func (e *ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ExitCodeError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCodeForResult returns the exit code for the given Jenkins build result
+func ExitCodeForResult(result string, unstableOK bool) int {
+	switch result {
+	case "SUCCESS":
+		return ExitSuccess
+	case "UNSTABLE":
+		if unstableOK {
+			return ExitSuccess
+		}
+		return ExitUnstable
+	case "ABORTED":
+		return ExitAborted
+	case "NOT_BUILT":
+		return ExitNotBuilt
+	default:
+		return ExitFailure
+	}
+}
+
+// BuildResultError returns an error with the exit code for the result of the given build or nil
+// if the build was successful
+func BuildResultError(jobName string, buildNumber int, result string, unstableOK bool) error {
+	code := ExitCodeForResult(result, unstableOK)
+	if code == ExitSuccess {
+		return nil
+	}
+	return NewExitCodeError(code, fmt.Errorf("build %s/%d result is %s", jobName, buildNumber, result))
+}
+
+// IsTimeout returns true if the error is caused by a timeout
+func IsTimeout(err error) bool {
+	exitErr := &ExitCodeError{}
+	return errors.As(err, &exitErr) && exitErr.Code == ExitTimeout
+}
+
+// ExitCode returns the exit code for the given error which is ExitSuccess if there is no error
+// and ExitError if the error has no specific exit code
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+	exitErr := &ExitCodeError{}
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return ExitError
+}
+
+// CheckErr exits the process if there is an error using the exit code of the error if it has one
+func CheckErr(err error) {
+	exitErr := &ExitCodeError{}
+	if errors.As(err, &exitErr) {
+		log.Logger().Error(err.Error())
+		os.Exit(exitErr.Code)
+	}
+	helper.CheckErr(err)
+}
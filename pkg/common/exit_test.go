@@ -0,0 +1,50 @@
+package common_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCodeForResult(t *testing.T) {
+	testCases := []struct {
+		result     string
+		unstableOK bool
+		expected   int
+	}{
+		{"SUCCESS", false, common.ExitSuccess},
+		{"FAILURE", false, common.ExitFailure},
+		{"UNSTABLE", false, common.ExitUnstable},
+		{"UNSTABLE", true, common.ExitSuccess},
+		{"ABORTED", false, common.ExitAborted},
+		{"NOT_BUILT", false, common.ExitNotBuilt},
+		{"", false, common.ExitFailure},
+	}
+	for _, tc := range testCases {
+		actual := common.ExitCodeForResult(tc.result, tc.unstableOK)
+		assert.Equal(t, tc.expected, actual, "exit code for result %s with unstableOK %v", tc.result, tc.unstableOK)
+	}
+}
+
+func TestBuildResultErrorIsFoundWhenWrapped(t *testing.T) {
+	err := common.BuildResultError("myowner/myrepo/master", 3, "ABORTED", false)
+	require.Error(t, err, "should have an error for an aborted build")
+
+	wrapped := errors.Wrap(err, "failed")
+	exitErr := &common.ExitCodeError{}
+	require.True(t, errors.As(wrapped, &exitErr), "should find the exit code error")
+	assert.Equal(t, common.ExitAborted, exitErr.Code, "exit code")
+
+	assert.NoError(t, common.BuildResultError("myowner/myrepo/master", 3, "SUCCESS", false), "successful build")
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, common.ExitSuccess, common.ExitCode(nil), "no error")
+	assert.Equal(t, common.ExitError, common.ExitCode(errors.New("connection refused")), "plain error")
+
+	err := errors.Wrap(common.BuildResultError("myowner/myrepo/master", 3, "UNSTABLE", false), "failed")
+	assert.Equal(t, common.ExitUnstable, common.ExitCode(err), "wrapped build result error")
+}
@@ -3,6 +3,7 @@ package common
 import (
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx-logging/pkg/log"
@@ -79,3 +80,17 @@ func GetIOFileHandles(h *util.IOFileHandles) util.IOFileHandles {
 	}
 	return *h
 }
+
+// DurationFromEnv returns the duration in the given environment variable or the default value if it is not set
+func DurationFromEnv(name string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Logger().Warnf("ignoring the $%s value %s as it is not a valid duration: %s", name, value, err.Error())
+		return defaultValue
+	}
+	return d
+}
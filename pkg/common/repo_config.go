@@ -0,0 +1,74 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// RepoConfigFileName the name of the configuration file in a git repository
+const RepoConfigFileName = ".tp.yaml"
+
+// RepoConfig the configuration of trigger-pipeline for a git repository
+type RepoConfig struct {
+	// JobTemplate the default Go template file used to create pipeline jobs
+	JobTemplate string `json:"jobTemplate,omitempty"`
+
+	// FolderTemplate the default Go template file used to create folders
+	FolderTemplate string `json:"folderTemplate,omitempty"`
+}
+
+// LoadRepoConfig loads the configuration file from the given directory or the nearest parent directory
+// up to the root of the git repository. Any file paths in the configuration are made relative to the
+// directory containing the configuration file. Returns an empty configuration if there is no file
+func LoadRepoConfig(dir string) (*RepoConfig, error) {
+	config := &RepoConfig{}
+	path, err := findRepoConfig(dir)
+	if err != nil || path == "" {
+		return config, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config, errors.Wrapf(err, "failed to load %s", path)
+	}
+	err = yaml.Unmarshal(data, config)
+	if err != nil {
+		return config, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	configDir := filepath.Dir(path)
+	config.JobTemplate = resolvePath(configDir, config.JobTemplate)
+	config.FolderTemplate = resolvePath(configDir, config.FolderTemplate)
+	return config, nil
+}
+
+func findRepoConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find the absolute path of %s", dir)
+	}
+	for {
+		path := filepath.Join(dir, RepoConfigFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		// lets not look outside of the git repository
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+func resolvePath(dir string, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
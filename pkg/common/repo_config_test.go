@@ -0,0 +1,34 @@
+package common_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRepoConfig(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test-repo-config-")
+	require.NoError(t, err, "failed to create temp dir")
+	defer os.RemoveAll(tmpDir)
+
+	subDir := filepath.Join(tmpDir, "charts", "myapp")
+	require.NoError(t, os.MkdirAll(subDir, 0755), "failed to create sub dir")
+	require.NoError(t, os.Mkdir(filepath.Join(tmpDir, ".git"), 0755), "failed to create .git dir")
+
+	config, err := common.LoadRepoConfig(subDir)
+	require.NoError(t, err, "should not fail without a config file")
+	assert.Equal(t, &common.RepoConfig{}, config, "empty config")
+
+	data := []byte("jobTemplate: jenkins/job.xml.tmpl\nfolderTemplate: /templates/folder.xml.tmpl\n")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, common.RepoConfigFileName), data, 0644), "failed to write config")
+
+	config, err = common.LoadRepoConfig(subDir)
+	require.NoError(t, err, "failed to load config")
+	assert.Equal(t, filepath.Join(tmpDir, "jenkins", "job.xml.tmpl"), config.JobTemplate, "job template relative to the config file")
+	assert.Equal(t, "/templates/folder.xml.tmpl", config.FolderTemplate, "absolute folder template")
+}
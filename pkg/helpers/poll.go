@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/common"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+)
+
+// Poll polls the given condition until it returns true or the timeout expires. If the timeout expires
+// the error has the timeout exit code. Any error returned by the condition is returned as is even if the
+// timeout has expired so that only a real timeout is reported as one
+func Poll(interval time.Duration, timeout time.Duration, message string, condition gojenkins.ConditionFunc) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		done, err := condition()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return common.NewExitCodeError(common.ExitTimeout, fmt.Errorf("timed out after %s waiting for %s", timeout.String(), message))
+		}
+		if remaining < interval {
+			time.Sleep(remaining)
+		} else {
+			time.Sleep(interval)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package helpers_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/common"
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoll(t *testing.T) {
+	calls := 0
+	err := helpers.Poll(time.Millisecond, time.Second, "done", func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	require.NoError(t, err, "should have completed")
+	assert.Equal(t, 3, calls, "calls")
+
+	err = helpers.Poll(time.Millisecond, 10*time.Millisecond, "never", func() (bool, error) {
+		return false, nil
+	})
+	require.Error(t, err, "should have timed out")
+	assert.True(t, common.IsTimeout(err), "should be a timeout error")
+
+	err = helpers.Poll(time.Millisecond, 0, "failing", func() (bool, error) {
+		time.Sleep(5 * time.Millisecond)
+		return false, errors.New("401 Unauthorized")
+	})
+	require.Error(t, err, "should have failed")
+	assert.False(t, common.IsTimeout(err), "an error after the deadline should not be a timeout")
+}
@@ -0,0 +1,60 @@
+package jenkinsutil
+
+import (
+	"regexp"
+	"strings"
+
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/pkg/errors"
+)
+
+// FilterArtifacts returns the artifacts whose relative path matches any of the glob patterns or all the artifacts
+// if there are no patterns. A '*' matches any characters within a directory and a '**' matches any number of
+// directories so that '**/*.jar' matches all the jar files
+func FilterArtifacts(artifacts []gojenkins.Artifact, globs []string) ([]gojenkins.Artifact, error) {
+	if len(globs) == 0 {
+		return artifacts, nil
+	}
+	var regexes []*regexp.Regexp
+	for _, glob := range globs {
+		r, err := globRegex(glob)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid glob %s", glob)
+		}
+		regexes = append(regexes, r)
+	}
+	var answer []gojenkins.Artifact
+	for _, artifact := range artifacts {
+		for _, r := range regexes {
+			if r.MatchString(artifact.RelativePath) {
+				answer = append(answer, artifact)
+				break
+			}
+		}
+	}
+	return answer, nil
+}
+
+// globRegex converts the glob pattern into a regular expression
+func globRegex(glob string) (*regexp.Regexp, error) {
+	var buf strings.Builder
+	buf.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			buf.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			buf.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			buf.WriteString("[^/]*")
+		case glob[i] == '?':
+			buf.WriteString("[^/]")
+		default:
+			buf.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	buf.WriteString("$")
+	return regexp.Compile(buf.String())
+}
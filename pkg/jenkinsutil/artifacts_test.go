@@ -0,0 +1,71 @@
+package jenkinsutil_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterArtifacts(t *testing.T) {
+	artifacts := []gojenkins.Artifact{
+		{FileName: "app.jar", RelativePath: "app.jar"},
+		{FileName: "lib.jar", RelativePath: "target/libs/lib.jar"},
+		{FileName: "report.xml", RelativePath: "target/reports/report.xml"},
+	}
+	testCases := []struct {
+		globs    []string
+		expected []string
+	}{
+		{nil, []string{"app.jar", "target/libs/lib.jar", "target/reports/report.xml"}},
+		{[]string{"**/*.jar"}, []string{"app.jar", "target/libs/lib.jar"}},
+		{[]string{"*.jar"}, []string{"app.jar"}},
+		{[]string{"target/*/report.?ml", "target/**"}, []string{"target/libs/lib.jar", "target/reports/report.xml"}},
+		{[]string{"*.zip"}, nil},
+	}
+	for _, tc := range testCases {
+		filtered, err := jenkinsutil.FilterArtifacts(artifacts, tc.globs)
+		require.NoError(t, err, "failed to filter artifacts for %v", tc.globs)
+		var paths []string
+		for _, a := range filtered {
+			paths = append(paths, a.RelativePath)
+		}
+		assert.Equal(t, tc.expected, paths, "artifacts for %v", tc.globs)
+	}
+}
+
+func TestDownloadArtifact(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/job/myjob/3/artifact/target/my app.jar":
+			fmt.Fprint(w, "jar contents")
+		case "/job/myjob/3/artifact/truncated.jar":
+			w.Header().Set("Content-Length", "100")
+			fmt.Fprint(w, "partial")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := jenkinsutil.NewClient(&gojenkins.Auth{}, server.URL, server.Client())
+	build := gojenkins.Build{Number: 3, Url: server.URL + "/job/myjob/3/"}
+
+	var buf bytes.Buffer
+	size, err := client.DownloadArtifact(build, gojenkins.Artifact{RelativePath: "target/my app.jar"}, &buf)
+	require.NoError(t, err, "failed to download artifact")
+	assert.Equal(t, int64(12), size, "size")
+	assert.Equal(t, "jar contents", buf.String(), "contents")
+
+	_, err = client.DownloadArtifact(build, gojenkins.Artifact{RelativePath: "truncated.jar"}, &bytes.Buffer{})
+	assert.Error(t, err, "should fail for a truncated artifact")
+
+	_, err = client.DownloadArtifact(build, gojenkins.Artifact{RelativePath: "missing.jar"}, &bytes.Buffer{})
+	assert.Error(t, err, "should fail for a missing artifact")
+}
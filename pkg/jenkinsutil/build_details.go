@@ -0,0 +1,50 @@
+package jenkinsutil
+
+import (
+	"net/url"
+)
+
+// Parameters returns the parameters of the build along with the names of any parameters whose values
+// cannot be passed to a new build such as password parameters whose values are not returned by Jenkins
+func (d *BuildDetails) Parameters() (url.Values, []string) {
+	values := url.Values{}
+	var missing []string
+	for _, action := range d.Actions {
+		for _, p := range action.Parameters {
+			if p.Name == "" {
+				continue
+			}
+			if p.Value == nil {
+				missing = append(missing, p.Name)
+				continue
+			}
+			value, err := parameterValue(p.Name, p.Value)
+			if err != nil {
+				missing = append(missing, p.Name)
+				continue
+			}
+			values.Add(p.Name, value)
+		}
+	}
+	return values, missing
+}
+
+// Causes returns the causes of the build
+func (d *BuildDetails) Causes() []BuildCause {
+	var answer []BuildCause
+	for _, action := range d.Actions {
+		answer = append(answer, action.Causes...)
+	}
+	return answer
+}
+
+// CauseDescriptions returns the short descriptions of the causes of the build
+func (d *BuildDetails) CauseDescriptions() []string {
+	var answer []string
+	for _, cause := range d.Causes() {
+		if cause.ShortDescription != "" {
+			answer = append(answer, cause.ShortDescription)
+		}
+	}
+	return answer
+}
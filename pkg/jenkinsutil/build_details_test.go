@@ -0,0 +1,32 @@
+package jenkinsutil_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDetailsParametersAndCauses(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "builds", "details.json"))
+	require.NoError(t, err, "failed to load build details")
+
+	details := &jenkinsutil.BuildDetails{}
+	err = json.Unmarshal(data, details)
+	require.NoError(t, err, "failed to parse build details")
+
+	params, missing := details.Parameters()
+	expected := url.Values{
+		"VERSION": []string{"1.2.3"},
+		"RELEASE": []string{"true"},
+	}
+	assert.Equal(t, expected, params, "build parameters")
+	assert.Equal(t, []string{"TOKEN"}, missing, "parameters without values")
+	assert.Equal(t, []string{"Started by user admin"}, details.CauseDescriptions(), "build causes")
+	assert.Equal(t, "admin", details.Causes()[0].UserID, "cause user")
+}
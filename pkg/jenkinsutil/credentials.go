@@ -0,0 +1,58 @@
+package jenkinsutil
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/common"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SecretKeyUsername the key of the username in a basic auth Secret
+	SecretKeyUsername = "username"
+
+	// SecretKeyPassword the key of the password in a basic auth Secret
+	SecretKeyPassword = "password"
+)
+
+var (
+	userRemoteConfigRegex = regexp.MustCompile(`(?s)<hudson\.plugins\.git\.UserRemoteConfig>.*?</hudson\.plugins\.git\.UserRemoteConfig>`)
+	credentialsIDRegex    = regexp.MustCompile(`<credentialsId>[^<]*</credentialsId>`)
+	remoteURLRegex        = regexp.MustCompile(`([ \t]*)<url>[^<]*</url>`)
+)
+
+// GitCredentialsFromSecret returns the git username and password from a Kubernetes Secret using either the
+// basic auth keys or the user and token keys used by the Jenkins server Secrets
+func GitCredentialsFromSecret(secret *v1.Secret) (string, string, error) {
+	username := string(secret.Data[SecretKeyUsername])
+	if username == "" {
+		username = string(secret.Data[common.SecretKeyUser])
+	}
+	password := string(secret.Data[SecretKeyPassword])
+	if password == "" {
+		password = string(secret.Data[common.SecretKeyToken])
+	}
+	if username == "" || password == "" {
+		return username, password, fmt.Errorf("the Secret %s should contain the keys %s and %s or %s and %s", secret.Name, SecretKeyUsername, SecretKeyPassword, common.SecretKeyUser, common.SecretKeyToken)
+	}
+	return username, password, nil
+}
+
+// SetPipelineCredentialsID sets the credentialsId of each git remote in the pipeline XML
+func SetPipelineCredentialsID(pipelineXML string, credentialsID string) string {
+	escaped, _ := xmlEscape(credentialsID)
+	element := "<credentialsId>" + escaped + "</credentialsId>"
+	return userRemoteConfigRegex.ReplaceAllStringFunc(pipelineXML, func(remote string) string {
+		if credentialsIDRegex.MatchString(remote) {
+			return credentialsIDRegex.ReplaceAllLiteralString(remote, element)
+		}
+		loc := remoteURLRegex.FindStringSubmatchIndex(remote)
+		if loc == nil {
+			return strings.Replace(remote, "</hudson.plugins.git.UserRemoteConfig>", element+"</hudson.plugins.git.UserRemoteConfig>", 1)
+		}
+		indent := remote[loc[2]:loc[3]]
+		return remote[:loc[1]] + "\n" + indent + element + remote[loc[1]:]
+	})
+}
@@ -0,0 +1,54 @@
+package jenkinsutil_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestSetPipelineCredentialsID(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "pipeline", "config.xml"))
+	require.NoError(t, err, "failed to load config.xml")
+
+	pipelineXML := jenkinsutil.SetPipelineCredentialsID(string(data), "github-token")
+	config, err := jenkinsutil.ParsePipelineConfig(pipelineXML)
+	require.NoError(t, err, "failed to parse pipeline XML with credentials")
+	assert.Equal(t, "github-token", config.CredentialsID, "added credentials")
+	assert.Equal(t, "https://github.com/myowner/myrepo.git", config.GitURL, "git URL")
+
+	pipelineXML = jenkinsutil.SetPipelineCredentialsID(pipelineXML, "other-token")
+	config, err = jenkinsutil.ParsePipelineConfig(pipelineXML)
+	require.NoError(t, err, "failed to parse pipeline XML with replaced credentials")
+	assert.Equal(t, "other-token", config.CredentialsID, "replaced credentials")
+}
+
+func TestGitCredentialsFromSecret(t *testing.T) {
+	secret := &v1.Secret{
+		Data: map[string][]byte{
+			"username": []byte("myuser"),
+			"password": []byte("mypassword"),
+		},
+	}
+	username, password, err := jenkinsutil.GitCredentialsFromSecret(secret)
+	require.NoError(t, err, "basic auth secret")
+	assert.Equal(t, "myuser", username, "username")
+	assert.Equal(t, "mypassword", password, "password")
+
+	secret.Data = map[string][]byte{
+		"user":  []byte("myuser"),
+		"token": []byte("mytoken"),
+	}
+	username, password, err = jenkinsutil.GitCredentialsFromSecret(secret)
+	require.NoError(t, err, "user and token secret")
+	assert.Equal(t, "myuser", username, "username")
+	assert.Equal(t, "mytoken", password, "token")
+
+	secret.Data = map[string][]byte{"username": []byte("myuser")}
+	_, _, err = jenkinsutil.GitCredentialsFromSecret(secret)
+	assert.Error(t, err, "should fail without a password")
+}
@@ -0,0 +1,149 @@
+package jenkinsutil
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/jenkins-x/jx-logging/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// crumb the CSRF crumb returned by the Jenkins crumb issuer
+type crumb struct {
+	Crumb             string `json:"crumb"`
+	CrumbRequestField string `json:"crumbRequestField"`
+}
+
+// crumbTransport adds the Jenkins CSRF crumb to all mutating requests. The crumb is fetched
+// lazily from the crumb issuer and refreshed if Jenkins rejects it. As Jenkins ties a crumb to
+// the HTTP session the session cookie is stored in the cookie jar of the HTTP client
+type crumbTransport struct {
+	baseURL string
+	base    http.RoundTripper
+	jar     http.CookieJar
+
+	lock     sync.Mutex
+	crumb    *crumb
+	disabled bool
+}
+
+func newCrumbTransport(baseURL string, base http.RoundTripper, jar http.CookieJar) *crumbTransport {
+	return &crumbTransport{
+		baseURL: baseURL,
+		base:    base,
+		jar:     jar,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *crumbTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.base.RoundTrip(req)
+	}
+
+	c, err := t.getCrumb(req, false)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(t.withCrumb(req, c))
+	if err != nil || c == nil || resp.StatusCode != http.StatusForbidden || !isReplayable(req) {
+		return resp, err
+	}
+
+	// the crumb may have expired along with the session so lets refresh it and try again
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(strings.ToLower(string(data)), "crumb") {
+		resp.Body = ioutil.NopCloser(strings.NewReader(string(data)))
+		return resp, nil
+	}
+	log.Logger().Debugf("refreshing the Jenkins crumb after the request to %s was rejected", req.URL.String())
+	c, err = t.getCrumb(req, true)
+	if err != nil {
+		return nil, err
+	}
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(t.withCrumb(retry, c))
+}
+
+// getCrumb returns the current crumb, fetching it if we don't have one yet or refresh is true.
+// Returns nil if CSRF protection is disabled in Jenkins
+func (t *crumbTransport) getCrumb(req *http.Request, refresh bool) (*crumb, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.disabled {
+		return nil, nil
+	}
+	if t.crumb != nil && !refresh {
+		return t.crumb, nil
+	}
+
+	u := util.UrlJoin(t.baseURL, "crumbIssuer/api/json")
+	crumbReq, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create request for %s", u)
+	}
+	// lets use the same credentials as the request we are protecting
+	crumbReq.Header.Set("Authorization", req.Header.Get("Authorization"))
+	for _, cookie := range t.jar.Cookies(crumbReq.URL) {
+		crumbReq.AddCookie(cookie)
+	}
+
+	resp, err := t.base.RoundTrip(crumbReq)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the Jenkins crumb from %s", u)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		log.Logger().Debugf("the Jenkins server at %s has no crumb issuer so CSRF protection is disabled", t.baseURL)
+		t.disabled = true
+		return nil, nil
+	}
+	if resp.StatusCode >= 400 {
+		return nil, errors.Errorf("failed to get the Jenkins crumb from %s: %s", u, resp.Status)
+	}
+	t.jar.SetCookies(crumbReq.URL, resp.Cookies())
+
+	c := &crumb{}
+	err = json.NewDecoder(resp.Body).Decode(c)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the Jenkins crumb from %s", u)
+	}
+	t.crumb = c
+	return c, nil
+}
+
+// withCrumb returns a copy of the request with the crumb header and the session cookie the
+// crumb is tied to
+func (t *crumbTransport) withCrumb(req *http.Request, c *crumb) *http.Request {
+	if c == nil {
+		return req
+	}
+	answer := req.Clone(req.Context())
+	answer.Header.Set(c.CrumbRequestField, c.Crumb)
+	answer.Header.Del("Cookie")
+	for _, cookie := range t.jar.Cookies(answer.URL) {
+		answer.AddCookie(cookie)
+	}
+	return answer
+}
+
+func isReplayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
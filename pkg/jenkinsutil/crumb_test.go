@@ -0,0 +1,93 @@
+package jenkinsutil_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCrumbServer a fake Jenkins which ties a crumb to each session
+type fakeCrumbServer struct {
+	sessions    int
+	validCrumbs map[string]string
+	builds      int
+}
+
+func (s *fakeCrumbServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/crumbIssuer/api/json":
+		s.sessions++
+		session := fmt.Sprintf("session%d", s.sessions)
+		crumb := fmt.Sprintf("crumb%d", s.sessions)
+		s.validCrumbs[session] = crumb
+		http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: session, Path: "/"})
+		fmt.Fprintf(w, `{"crumb":"%s","crumbRequestField":"Jenkins-Crumb"}`, crumb)
+
+	case "/job/myjob/build":
+		cookie, err := r.Cookie("JSESSIONID")
+		if err != nil || s.validCrumbs[cookie.Value] != r.Header.Get("Jenkins-Crumb") {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, "No valid crumb was included in the request")
+			return
+		}
+		s.builds++
+		w.Header().Set("Location", "http://"+r.Host+"/queue/item/1/")
+		w.WriteHeader(http.StatusCreated)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestCrumbIsAddedAndRefreshed(t *testing.T) {
+	s := &fakeCrumbServer{validCrumbs: map[string]string{}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	jsvc := &jenkinsutil.JenkinsServer{
+		Name: "test",
+		URL:  server.URL,
+		Auth: gojenkins.Auth{Username: "admin", ApiToken: "token"},
+	}
+	client, err := jsvc.CreateClient()
+	require.NoError(t, err, "failed to create client")
+
+	job := gojenkins.Job{Name: "myjob", FullName: "myjob", Url: server.URL + "/job/myjob/"}
+	_, err = client.BuildWithParameters(job, nil)
+	require.NoError(t, err, "failed to trigger build with a crumb")
+	assert.Equal(t, 1, s.sessions, "should have fetched a single crumb")
+
+	// lets expire the session so the crumb is refreshed
+	s.validCrumbs = map[string]string{}
+	_, err = client.BuildWithParameters(job, nil)
+	require.NoError(t, err, "failed to trigger build after the crumb expired")
+	assert.Equal(t, 2, s.sessions, "should have refreshed the crumb")
+	assert.Equal(t, 2, s.builds, "builds triggered")
+}
+
+func TestNoCrumbIssuer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/job/myjob/build" {
+			w.Header().Set("Location", "http://"+r.Host+"/queue/item/1/")
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	jsvc := &jenkinsutil.JenkinsServer{Name: "test", URL: server.URL}
+	client, err := jsvc.CreateClient()
+	require.NoError(t, err, "failed to create client")
+
+	job := gojenkins.Job{Name: "myjob", FullName: "myjob", Url: server.URL + "/job/myjob/"}
+	queueItemURL, err := client.BuildWithParameters(job, nil)
+	require.NoError(t, err, "failed to trigger build without a crumb issuer")
+	assert.Equal(t, server.URL+"/queue/item/1/", queueItemURL, "queue item URL")
+}
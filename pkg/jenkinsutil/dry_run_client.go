@@ -0,0 +1,292 @@
+package jenkinsutil
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+const (
+	// DryRunCreate a job would be created
+	DryRunCreate = "create"
+
+	// DryRunUpdate the config.xml of a job would be updated
+	DryRunUpdate = "update"
+
+	// DryRunScan a multi branch project would be scanned
+	DryRunScan = "scan"
+
+	// DryRunBuild a build would be queued
+	DryRunBuild = "build"
+
+	// DryRunStop a build would be stopped
+	DryRunStop = "stop"
+
+	// DryRunCancelQueueItem a queued build would be removed from the queue
+	DryRunCancelQueueItem = "cancelQueueItem"
+
+	// DryRunCreateCredential a username and password credential would be created
+	DryRunCreateCredential = "createCredential"
+)
+
+// DryRunAction a change which would have been made to Jenkins
+type DryRunAction struct {
+	// Action the kind of change
+	Action string `json:"action"`
+
+	// Job the full name of the job
+	Job string `json:"job,omitempty"`
+
+	// Credential the id of the credential which is created
+	Credential string `json:"credential,omitempty"`
+
+	// Class the XML element name of the job which is created or updated
+	Class string `json:"class,omitempty"`
+
+	// XML the config.xml of the job which is created or updated
+	XML string `json:"xml,omitempty"`
+
+	// Parameters the parameters of the build
+	Parameters url.Values `json:"parameters,omitempty"`
+
+	// BuildNumber the number of the build which is stopped
+	BuildNumber int `json:"buildNumber,omitempty"`
+
+	// QueueID the id of the queue item which is removed
+	QueueID int `json:"queueId,omitempty"`
+}
+
+// IsFolder returns true if the action creates or updates a folder
+func (a *DryRunAction) IsFolder() bool {
+	return strings.HasSuffix(a.Class, ".Folder")
+}
+
+// DryRunClient a client which reads from Jenkins but records any changes rather than making them so
+// that the same code can be used to find out what a command would do
+type DryRunClient struct {
+	Client
+
+	// Actions the changes which would have been made in order
+	Actions []DryRunAction
+
+	lock    sync.Mutex
+	created map[string]string
+	scanned map[string]bool
+}
+
+var _ Client = (*DryRunClient)(nil)
+
+// NewDryRunClient creates a dry run client which reads from the given client
+func NewDryRunClient(client Client) *DryRunClient {
+	return &DryRunClient{
+		Client:  client,
+		created: map[string]string{},
+		scanned: map[string]bool{},
+	}
+}
+
+func (c *DryRunClient) record(action DryRunAction) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.Actions = append(c.Actions, action)
+	switch action.Action {
+	case DryRunCreate:
+		c.created[action.Job] = action.Class
+	case DryRunScan:
+		c.scanned[action.Job] = true
+	}
+}
+
+// createdClass returns the class of the job which would have been created and whether it would have been created
+func (c *DryRunClient) createdClass(fullName string) (string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	class, ok := c.created[fullName]
+	return class, ok
+}
+
+func (c *DryRunClient) isScanned(fullName string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.scanned[fullName]
+}
+
+// plannedJob returns the job of the given class which would have been created
+func (c *DryRunClient) plannedJob(class string, paths ...string) gojenkins.Job {
+	return gojenkins.Job{
+		Class:    class,
+		Name:     paths[len(paths)-1],
+		FullName: strings.Join(paths, "/"),
+		Url:      util.UrlJoin(c.BaseURL(), gojenkins.FullJobPath(paths...)),
+	}
+}
+
+// GetJobByPath returns the job from Jenkins or the job which would have been created. A scan of an organization
+// folder would create the multi branch projects inside it
+func (c *DryRunClient) GetJobByPath(paths ...string) (gojenkins.Job, error) {
+	job, err := c.Client.GetJobByPath(paths...)
+	if err == nil || len(paths) == 0 {
+		return job, err
+	}
+	if class, ok := c.createdClass(strings.Join(paths, "/")); ok {
+		return c.plannedJob(class, paths...), nil
+	}
+	if len(paths) == 2 && c.isScanned(paths[0]) {
+		return c.plannedJob(MultiBranchProjectClass, paths...), nil
+	}
+	return job, err
+}
+
+// GetMultiBranchJob returns the branch job from Jenkins or the job which a scan of the multi branch project or
+// organization folder would have created
+func (c *DryRunClient) GetMultiBranchJob(organisation, repository, branch string) (gojenkins.Job, error) {
+	job, err := c.Client.GetMultiBranchJob(organisation, repository, branch)
+	if err != nil && (c.isScanned(organisation+"/"+repository) || c.isScanned(organisation)) {
+		return c.plannedJob("", organisation, repository, branch), nil
+	}
+	return job, err
+}
+
+// CreateJobWithXML records the job which would be created
+func (c *DryRunClient) CreateJobWithXML(jobXML string, jobName string) error {
+	c.record(DryRunAction{Action: DryRunCreate, Job: jobName, Class: xmlRootElement(jobXML), XML: jobXML})
+	return nil
+}
+
+// CreateFolderJobWithXML records the job which would be created inside the folders
+func (c *DryRunClient) CreateFolderJobWithXML(jobXML string, folders string, jobName string) error {
+	fullName := strings.Replace(folders, "/job/", "/", -1) + "/" + jobName
+	c.record(DryRunAction{Action: DryRunCreate, Job: fullName, Class: xmlRootElement(jobXML), XML: jobXML})
+	return nil
+}
+
+// UpdateJobWithXML records the job which would be updated
+func (c *DryRunClient) UpdateJobWithXML(job gojenkins.Job, jobXML string) error {
+	c.record(DryRunAction{Action: DryRunUpdate, Job: job.FullName, Class: xmlRootElement(jobXML), XML: jobXML})
+	return nil
+}
+
+// Build records the build or multi branch project scan which would be queued
+func (c *DryRunClient) Build(job gojenkins.Job, params url.Values) error {
+	action := DryRunBuild
+	if job.Class == MultiBranchProjectClass || job.Class == OrganizationFolderClass || len(job.Jobs) > 0 {
+		action = DryRunScan
+	}
+	c.record(DryRunAction{Action: action, Job: job.FullName, Parameters: params})
+	return nil
+}
+
+// GetOrganizationScanResult returns a successful result for an organization folder scan which would be queued
+func (c *DryRunClient) GetOrganizationScanResult(retries int, job gojenkins.Job) (string, error) {
+	if c.isScanned(job.FullName) {
+		return "SUCCESS", nil
+	}
+	return c.Client.GetOrganizationScanResult(retries, job)
+}
+
+// BuildWithParameters records the build which would be queued
+func (c *DryRunClient) BuildWithParameters(job gojenkins.Job, params url.Values) (string, error) {
+	c.record(DryRunAction{Action: DryRunBuild, Job: job.FullName, Parameters: params})
+	return "", nil
+}
+
+// StopBuild records the build which would be stopped
+func (c *DryRunClient) StopBuild(job gojenkins.Job, number int) error {
+	c.record(DryRunAction{Action: DryRunStop, Job: job.FullName, BuildNumber: number})
+	return nil
+}
+
+// CancelQueueItem records the queue item which would be removed
+func (c *DryRunClient) CancelQueueItem(id int) error {
+	c.record(DryRunAction{Action: DryRunCancelQueueItem, QueueID: id})
+	return nil
+}
+
+// Post fails as arbitrary changes cannot be recorded
+func (c *DryRunClient) Post(string, url.Values, interface{}) error {
+	return dryRunError("Post")
+}
+
+// CreateJob fails as it is not used by trigger-pipeline
+func (c *DryRunClient) CreateJob(gojenkins.JobItem, string) error {
+	return dryRunError("CreateJob")
+}
+
+// UpdateJob fails as it is not used by trigger-pipeline
+func (c *DryRunClient) UpdateJob(gojenkins.JobItem, string) error {
+	return dryRunError("UpdateJob")
+}
+
+// DeleteJob fails as it is not used by trigger-pipeline
+func (c *DryRunClient) DeleteJob(gojenkins.Job) error {
+	return dryRunError("DeleteJob")
+}
+
+// RemoveJob fails as it is not used by trigger-pipeline
+func (c *DryRunClient) RemoveJob(string) error {
+	return dryRunError("RemoveJob")
+}
+
+// Reload fails as it is not used by trigger-pipeline
+func (c *DryRunClient) Reload() error {
+	return dryRunError("Reload")
+}
+
+// Restart fails as it is not used by trigger-pipeline
+func (c *DryRunClient) Restart() error {
+	return dryRunError("Restart")
+}
+
+// SafeRestart fails as it is not used by trigger-pipeline
+func (c *DryRunClient) SafeRestart() error {
+	return dryRunError("SafeRestart")
+}
+
+// QuietDown fails as it is not used by trigger-pipeline
+func (c *DryRunClient) QuietDown() error {
+	return dryRunError("QuietDown")
+}
+
+// CreateCredential records the credential which would be created
+func (c *DryRunClient) CreateCredential(id string, username string, password string) error {
+	c.record(DryRunAction{Action: DryRunCreateCredential, Credential: id})
+	return nil
+}
+
+// AddJobToView fails as it is not used by trigger-pipeline
+func (c *DryRunClient) AddJobToView(string, gojenkins.Job) error {
+	return dryRunError("AddJobToView")
+}
+
+// CreateView fails as it is not used by trigger-pipeline
+func (c *DryRunClient) CreateView(gojenkins.ListView) error {
+	return dryRunError("CreateView")
+}
+
+// SetBuildDescription fails as it is not used by trigger-pipeline
+func (c *DryRunClient) SetBuildDescription(gojenkins.Build, string) error {
+	return dryRunError("SetBuildDescription")
+}
+
+func dryRunError(operation string) error {
+	return fmt.Errorf("%s is not supported in dry run mode", operation)
+}
+
+// xmlRootElement returns the name of the root element of the XML document
+func xmlRootElement(text string) string {
+	decoder := xml.NewDecoder(strings.NewReader(stripXMLDeclaration(text)))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local
+		}
+	}
+}
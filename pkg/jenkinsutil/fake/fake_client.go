@@ -6,8 +6,10 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
 	gojenkins "github.com/jenkins-x/golang-jenkins"
 )
 
@@ -22,8 +24,48 @@ type FakeClient struct {
 	XMLJobs       []XMLJob
 	FolderXMLJobs []FolderXMLJob
 	BuildRequests []BuildRequest
+	QueueItems    []jenkinsutil.QueueItem
+	PipelineRuns  map[string]*jenkinsutil.PipelineRun
+	BuildDetails  map[string]*jenkinsutil.BuildDetails
+
+	Builds        []gojenkins.Build
+	StoppedBuilds []int
+
+	CancelledQueueItems []int
+	UpdatedJobs         []XMLJob
+	Credentials         []FakeCredential
+
+	// CredentialError if specified the error returned when getting credentials
+	CredentialError error
+
+	// ScanBranches the branch jobs which a scan of a created multi branch project discovers
+	ScanBranches []string
+
+	// OrganizationScanJobs the multi branch projects which a scan of an organization folder in Jobs discovers
+	OrganizationScanJobs []gojenkins.Job
+
+	// ConsoleLog the build log written when tailing a build or returned as the console output of a build
+	ConsoleLog string
+
+	// TailLogError if specified the error returned after writing the build log when tailing
+	TailLogError error
+
+	// IndexingLog the log of the branch indexing of multi branch projects
+	IndexingLog string
+
+	// BuildArtifacts the archived artifacts of the builds which are not in Builds
+	BuildArtifacts []gojenkins.Artifact
+
+	// ArtifactContents the contents of the archived artifacts of builds indexed by their relative path
+	ArtifactContents map[string]string
+
+	indexing map[string]*jenkinsutil.Indexing
+
+	// BuildResult if specified the builds complete straight away with this result
+	BuildResult string
 
 	httpClient *http.Client
+	lock       sync.Mutex
 }
 
 // XMLJob represents a fake created XML Job
@@ -39,6 +81,13 @@ type FolderXMLJob struct {
 	JobName    string
 }
 
+// FakeCredential represents a fake created username and password credential
+type FakeCredential struct {
+	ID       string
+	Username string
+	Password string
+}
+
 // BuildRequest for recording build requests
 type BuildRequest struct {
 	Job    gojenkins.Job
@@ -50,7 +99,7 @@ func (j *FolderXMLJob) FullJobPath() string {
 	return "/job/" + j.Folder + "/job/" + j.JobName
 }
 
-var _ gojenkins.JenkinsClient = (*FakeClient)(nil)
+var _ jenkinsutil.Client = (*FakeClient)(nil)
 
 func (f *FakeClient) GetJobs() ([]gojenkins.Job, error) {
 	return f.Jobs, nil
@@ -93,8 +142,66 @@ func (f *FakeClient) GetJobConfig(string) (gojenkins.JobItem, error) {
 	panic("implement me")
 }
 
-func (f *FakeClient) GetBuild(gojenkins.Job, int) (gojenkins.Build, error) {
-	panic("implement me")
+func (f *FakeClient) GetBuild(job gojenkins.Job, number int) (gojenkins.Build, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var build gojenkins.Build
+	build.Number = number
+	build.Building = f.BuildResult == ""
+	build.Result = f.BuildResult
+	build.Url = fmt.Sprintf("%s/%d", job.Url, build.Number)
+	build.Artifacts = f.BuildArtifacts
+	for _, b := range f.Builds {
+		if b.Number == number {
+			build = b
+		}
+	}
+	return f.stoppedBuild(build), nil
+}
+
+// stoppedBuild returns the build as aborted if it has been stopped
+func (f *FakeClient) stoppedBuild(build gojenkins.Build) gojenkins.Build {
+	for _, n := range f.StoppedBuilds {
+		if n == build.Number {
+			build.Building = false
+			build.Result = "ABORTED"
+		}
+	}
+	return build
+}
+
+func (f *FakeClient) GetBuilds(job gojenkins.Job) ([]gojenkins.Build, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var answer []gojenkins.Build
+	for _, b := range f.Builds {
+		answer = append(answer, f.stoppedBuild(b))
+	}
+	return answer, nil
+}
+
+func (f *FakeClient) GetJobQueueItems(job gojenkins.Job) ([]jenkinsutil.QueueItem, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var answer []jenkinsutil.QueueItem
+	for _, item := range f.QueueItems {
+		if item.Task != nil && item.Task.URL == job.Url && item.Executable == nil && !item.Cancelled {
+			answer = append(answer, item)
+		}
+	}
+	return answer, nil
+}
+
+func (f *FakeClient) CancelQueueItem(id int) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for i := range f.QueueItems {
+		if f.QueueItems[i].ID == id {
+			f.QueueItems[i].Cancelled = true
+		}
+	}
+	f.CancelledQueueItems = append(f.CancelledQueueItems, id)
+	return nil
 }
 
 var lastbuildnumber = 0
@@ -108,22 +215,29 @@ func (f *FakeClient) GetLastBuild(job gojenkins.Job) (gojenkins.Build, error) {
 	return build, nil
 }
 
-func (f *FakeClient) StopBuild(gojenkins.Job, int) error {
-	panic("implement me")
+func (f *FakeClient) StopBuild(job gojenkins.Job, number int) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.StoppedBuilds = append(f.StoppedBuilds, number)
+	return nil
 }
 
-func (f *FakeClient) GetMultiBranchJob(string, string, string) (gojenkins.Job, error) {
-	panic("implement me")
+func (f *FakeClient) GetMultiBranchJob(organisation, repository, branch string) (gojenkins.Job, error) {
+	return f.GetJobByPath(organisation, repository, branch)
 }
 
 func (f *FakeClient) GetJobByPath(paths ...string) (gojenkins.Job, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
 	var job gojenkins.Job
 
 	fullPath := gojenkins.FullJobPath(paths...)
 	for _, xj := range f.XMLJobs {
-		if xj.JobName == fullPath {
+		if gojenkins.FullJobPath(xj.JobName) == fullPath {
 			job.Name = xj.JobName
 			job.FullName = fullPath
+			job.Url = f.BaseURLValue + fullPath
 			return job, nil
 		}
 	}
@@ -133,6 +247,7 @@ func (f *FakeClient) GetJobByPath(paths ...string) (gojenkins.Job, error) {
 		if strings.HasPrefix(fullFolderPath, fullPath) {
 			job.Name = xj.JobName
 			job.FullName = fullPath
+			job.Url = f.BaseURLValue + fullPath
 			return job, nil
 		}
 	}
@@ -146,20 +261,27 @@ func (f *FakeClient) GetJobByPath(paths ...string) (gojenkins.Job, error) {
 			}
 
 		} else {
+			found := false
 			for _, j := range job.Jobs {
 				if j.Name == name {
 					job = j
-					continue
+					found = true
+					break
 				}
 			}
-			return gojenkins.Job{}, notFoundError()
+			if !found {
+				return gojenkins.Job{}, notFoundError()
+			}
 		}
 	}
-	return gojenkins.Job{}, notFoundError()
+	if len(paths) == 0 {
+		return job, notFoundError()
+	}
+	return job, nil
 }
 
 func (f *FakeClient) GetOrganizationScanResult(int, gojenkins.Job) (string, error) {
-	panic("implement me")
+	return "SUCCESS", nil
 }
 
 func (f *FakeClient) CreateJob(gojenkins.JobItem, string) error {
@@ -183,21 +305,48 @@ func (f *FakeClient) QuietDown() error {
 }
 
 func (f *FakeClient) CreateJobWithXML(jobItemXml string, jobName string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, xj := range f.XMLJobs {
+		if xj.JobName == jobName {
+			return fmt.Errorf("A job already exists with the name '%s'", jobName)
+		}
+	}
 	f.XMLJobs = append(f.XMLJobs, XMLJob{jobItemXml, jobName})
 	return nil
 }
 
 func (f *FakeClient) CreateFolderJobWithXML(jobItemXml string, folder string, jobName string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, xj := range f.FolderXMLJobs {
+		if xj.Folder == folder && xj.JobName == jobName {
+			return fmt.Errorf("A job already exists with the name '%s'", jobName)
+		}
+	}
 	f.FolderXMLJobs = append(f.FolderXMLJobs, FolderXMLJob{jobItemXml, folder, jobName})
 	return nil
 }
 
-func (f *FakeClient) GetCredential(string) (*gojenkins.Credentials, error) {
-	panic("implement me")
+func (f *FakeClient) GetCredential(id string) (*gojenkins.Credentials, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.CredentialError != nil {
+		return nil, f.CredentialError
+	}
+	for _, c := range f.Credentials {
+		if c.ID == id {
+			return &gojenkins.Credentials{}, nil
+		}
+	}
+	return nil, notFoundError()
 }
 
-func (f *FakeClient) CreateCredential(string, string, string) error {
-	panic("implement me")
+func (f *FakeClient) CreateCredential(id string, username string, password string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.Credentials = append(f.Credentials, FakeCredential{id, username, password})
+	return nil
 }
 
 func (f *FakeClient) DeleteJob(gojenkins.Job) error {
@@ -221,12 +370,148 @@ func (f *FakeClient) CreateView(gojenkins.ListView) error {
 }
 
 func (f *FakeClient) Build(job gojenkins.Job, values url.Values) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.BuildRequests = append(f.BuildRequests, BuildRequest{job, values})
+
+	// lets add the multi branch projects if this is a scan of an organization folder
+	if job.Class == jenkinsutil.OrganizationFolderClass {
+		for i := range f.Jobs {
+			if f.Jobs[i].Name == job.Name {
+				f.Jobs[i].Jobs = append(f.Jobs[i].Jobs, f.OrganizationScanJobs...)
+			}
+		}
+	}
+
+	// lets create the branch jobs if this is a scan of a created multi branch project
+	for _, xj := range f.FolderXMLJobs {
+		if f.BaseURLValue+xj.FullJobPath() == job.Url && strings.Contains(xj.JobItemXml, jenkinsutil.MultiBranchProjectClass) {
+			if f.indexing == nil {
+				f.indexing = map[string]*jenkinsutil.Indexing{}
+			}
+			f.indexing[job.Url] = &jenkinsutil.Indexing{
+				Result:    "SUCCESS",
+				Timestamp: time.Now().UnixNano() / int64(time.Millisecond),
+			}
+			for _, branch := range f.ScanBranches {
+				f.FolderXMLJobs = append(f.FolderXMLJobs, FolderXMLJob{
+					Folder:  xj.Folder + "/job/" + xj.JobName,
+					JobName: branch,
+				})
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func (f *FakeClient) BuildWithParameters(job gojenkins.Job, values url.Values) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
 	f.BuildRequests = append(f.BuildRequests, BuildRequest{job, values})
+
+	// lets assume the build starts straight away
+	lastbuildnumber++
+	id := len(f.QueueItems) + 1
+	item := jenkinsutil.QueueItem{
+		ID:  id,
+		URL: fmt.Sprintf("%s/queue/item/%d/", f.BaseURLValue, id),
+		Executable: &jenkinsutil.QueueExecutable{
+			Number: lastbuildnumber,
+			URL:    fmt.Sprintf("%s/%d", job.Url, lastbuildnumber),
+		},
+	}
+	f.QueueItems = append(f.QueueItems, item)
+	return item.URL, nil
+}
+
+func (f *FakeClient) GetQueueItem(queueItemURL string) (*jenkinsutil.QueueItem, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for i := range f.QueueItems {
+		if f.QueueItems[i].URL == queueItemURL {
+			item := f.QueueItems[i]
+			return &item, nil
+		}
+	}
+	return nil, notFoundError()
+}
+
+func (f *FakeClient) GetBuildDetails(buildURL string) (*jenkinsutil.BuildDetails, error) {
+	details := f.BuildDetails[buildURL]
+	if details == nil {
+		return &jenkinsutil.BuildDetails{}, nil
+	}
+	return details, nil
+}
+
+func (f *FakeClient) GetPipelineRun(buildURL string) (*jenkinsutil.PipelineRun, error) {
+	run := f.PipelineRuns[buildURL]
+	if run == nil {
+		return nil, notFoundError()
+	}
+	return run, nil
+}
+
+func (f *FakeClient) GetJobConfigXML(job gojenkins.Job) (string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, xj := range f.XMLJobs {
+		if xj.JobName == job.FullName {
+			return xj.JobItemXml, nil
+		}
+	}
+	for _, xj := range f.FolderXMLJobs {
+		if xj.FullJobPath() == job.FullName {
+			return xj.JobItemXml, nil
+		}
+	}
+	return "", notFoundError()
+}
+
+func (f *FakeClient) UpdateJobWithXML(job gojenkins.Job, jobXML string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.UpdatedJobs = append(f.UpdatedJobs, XMLJob{jobXML, job.FullName})
+	for i := range f.XMLJobs {
+		if f.XMLJobs[i].JobName == job.FullName {
+			f.XMLJobs[i].JobItemXml = jobXML
+		}
+	}
+	for i := range f.FolderXMLJobs {
+		if f.FolderXMLJobs[i].FullJobPath() == job.FullName {
+			f.FolderXMLJobs[i].JobItemXml = jobXML
+		}
+	}
 	return nil
 }
 
+func (f *FakeClient) GetIndexing(job gojenkins.Job) (*jenkinsutil.Indexing, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	indexing := f.indexing[job.Url]
+	if indexing == nil {
+		return nil, notFoundError()
+	}
+	answer := *indexing
+	return &answer, nil
+}
+
+func (f *FakeClient) GetIndexingLog(job gojenkins.Job) (string, error) {
+	return f.IndexingLog, nil
+}
+
+func (f *FakeClient) DownloadArtifact(build gojenkins.Build, artifact gojenkins.Artifact, out io.Writer) (int64, error) {
+	content, ok := f.ArtifactContents[artifact.RelativePath]
+	if !ok {
+		return 0, notFoundError()
+	}
+	n, err := io.WriteString(out, content)
+	return int64(n), err
+}
+
 func (f *FakeClient) GetBuildConsoleOutput(gojenkins.Build) ([]byte, error) {
-	panic("implement me")
+	return []byte(f.ConsoleLog), nil
 }
 
 func (f *FakeClient) GetQueue() (gojenkins.Queue, error) {
@@ -261,12 +546,30 @@ func (f *FakeClient) GetLogFromURL(string, int64, *gojenkins.LogData) error {
 	panic("implement me")
 }
 
-func (f *FakeClient) TailLog(string, io.Writer, time.Duration, time.Duration) error {
-	panic("implement me")
+func (f *FakeClient) TailLog(buildPath string, out io.Writer, interval time.Duration, timeout time.Duration) error {
+	_, err := io.WriteString(out, f.ConsoleLog)
+	if err != nil {
+		return err
+	}
+	return f.TailLogError
 }
 
-func (f *FakeClient) TailLogFunc(string, io.Writer) gojenkins.ConditionFunc {
-	panic("implement me")
+func (f *FakeClient) TailLogFunc(buildPath string, out io.Writer) gojenkins.ConditionFunc {
+	written := false
+	return func() (bool, error) {
+		if !written {
+			written = true
+			_, err := io.WriteString(out, f.ConsoleLog)
+			if err != nil {
+				return false, err
+			}
+		}
+		if f.TailLogError != nil {
+			return false, f.TailLogError
+		}
+		// lets keep tailing until the timeout if the build never completes
+		return f.BuildResult != "", nil
+	}
 }
 
 func (f *FakeClient) NewLogPoller(string, io.Writer) *gojenkins.LogPoller {
@@ -2,11 +2,13 @@ package jenkinsutil
 
 import (
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/helpers"
 	gojenkins "github.com/jenkins-x/golang-jenkins"
 	"github.com/jenkins-x/jx/v2/pkg/builds"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
@@ -69,7 +71,7 @@ func (o *JenkinsOptions) GetAllPipelineJobNames(jenkinsClient gojenkins.JenkinsC
 
 // CreateJenkinsClientFromSelector is given either a specific jenkins service name to use, uses the selector to find it
 // or prompts the user to pick one if not in batch mode.
-func (o *JenkinsOptions) CreateJenkinsClientFromSelector(jenkinsSelector *JenkinsSelectorOptions) (gojenkins.JenkinsClient, error) {
+func (o *JenkinsOptions) CreateJenkinsClientFromSelector(jenkinsSelector *JenkinsSelectorOptions) (Client, error) {
 	var err error
 	_, jsvc, err := o.PickCustomJenkinsName(jenkinsSelector, true)
 	if err != nil {
@@ -164,13 +166,9 @@ func (o *JenkinsOptions) AddJenkinsJobs(jenkins gojenkins.JenkinsClient, jobMap
 	}
 }
 
-// TailJenkinsBuildLog tail the build log of the given Jenkins jobs name
-func (o *JenkinsOptions) TailJenkinsBuildLog(jenkinsSelector *JenkinsSelectorOptions, jobName string, build *gojenkins.Build) error {
-	jenkins, err := o.CreateJenkinsClientFromSelector(jenkinsSelector)
-	if err != nil {
-		return nil
-	}
-
+// TailBuildLog tails the build log of the given build to the given writer until the build completes or the
+// timeout expires
+func (o *JenkinsOptions) TailBuildLog(jenkins gojenkins.JenkinsClient, jobName string, build *gojenkins.Build, out io.Writer, timeout time.Duration) error {
 	u, err := url.Parse(build.Url)
 	if err != nil {
 		return err
@@ -178,7 +176,8 @@ func (o *JenkinsOptions) TailJenkinsBuildLog(jenkinsSelector *JenkinsSelectorOpt
 	buildPath := u.Path
 	log.Logger().Infof("%s %s", "tailing the log of", fmt.Sprintf("%s #%d", jobName, build.Number))
 	// TODO Logger
-	return jenkins.TailLog(buildPath, o.GetIOFileHandles().Out, time.Second, time.Hour*100)
+	// lets poll the tail ourselves so that only the timeout expiring is reported as a timeout
+	return helpers.Poll(time.Second, timeout, fmt.Sprintf("the build %s #%d to complete", jobName, build.Number), jenkins.TailLogFunc(buildPath, out))
 }
 
 // GetJenkinsJobName returns the Jenkins job name
@@ -0,0 +1,434 @@
+package jenkinsutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/jenkins-x/jx/v2/pkg/jenkins"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// Client extends the gojenkins client with the REST operations that trigger-pipeline needs
+// which are not exposed by the underlying client
+type Client interface {
+	gojenkins.JenkinsClient
+
+	// BuildWithParameters triggers a build of the given job returning the URL of the queue item
+	// for the build. If any parameters are specified then the buildWithParameters endpoint is used
+	// rather than build
+	BuildWithParameters(job gojenkins.Job, params url.Values) (string, error)
+
+	// GetQueueItem returns the queue item for the given queue item URL
+	GetQueueItem(queueItemURL string) (*QueueItem, error)
+
+	// GetBuildDetails returns the details of the build which are not included in the gojenkins build
+	GetBuildDetails(buildURL string) (*BuildDetails, error)
+
+	// GetPipelineRun returns the stages of a pipeline build via the pipeline REST API
+	GetPipelineRun(buildURL string) (*PipelineRun, error)
+
+	// GetBuilds returns the recent builds of the given job
+	GetBuilds(job gojenkins.Job) ([]gojenkins.Build, error)
+
+	// GetJobQueueItems returns the items in the build queue for the given job
+	GetJobQueueItems(job gojenkins.Job) ([]QueueItem, error)
+
+	// CancelQueueItem removes the item with the given id from the build queue
+	CancelQueueItem(id int) error
+
+	// GetJobConfigXML returns the config.xml of the given job
+	GetJobConfigXML(job gojenkins.Job) (string, error)
+
+	// UpdateJobWithXML replaces the config.xml of the given job. Unlike UpdateJob this works for any
+	// kind of job as the XML is not marshalled from a freestyle JobItem
+	UpdateJobWithXML(job gojenkins.Job, jobXML string) error
+
+	// GetIndexing returns the last branch indexing of the given multi branch project
+	GetIndexing(job gojenkins.Job) (*Indexing, error)
+
+	// GetIndexingLog returns the log of the last branch indexing of the given multi branch project
+	GetIndexingLog(job gojenkins.Job) (string, error)
+
+	// DownloadArtifact writes the archived artifact of the given build to out returning the number of bytes
+	// written. Unlike GetArtifact the artifact is streamed rather than held in memory and an error is returned
+	// if the size does not match the Content-Length returned by Jenkins. The size of a response without a
+	// Content-Length, such as a chunked response, cannot be verified
+	DownloadArtifact(build gojenkins.Build, artifact gojenkins.Artifact, out io.Writer) (int64, error)
+}
+
+// QueueItem represents an item in the Jenkins build queue
+type QueueItem struct {
+	ID         int              `json:"id"`
+	URL        string           `json:"url"`
+	Why        string           `json:"why"`
+	Blocked    bool             `json:"blocked"`
+	Buildable  bool             `json:"buildable"`
+	Cancelled  bool             `json:"cancelled"`
+	Task       *QueueTask       `json:"task"`
+	Executable *QueueExecutable `json:"executable"`
+}
+
+// QueueTask the job of a queue item
+type QueueTask struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// QueueExecutable the build which has been started for a queue item
+type QueueExecutable struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+}
+
+// BuildDetails the details of a build which are not included in the gojenkins build
+type BuildDetails struct {
+	Number            int           `json:"number"`
+	QueueID           int           `json:"queueId"`
+	EstimatedDuration int64         `json:"estimatedDuration"`
+	Actions           []BuildAction `json:"actions"`
+}
+
+// BuildAction an action of a build such as its parameters or causes
+type BuildAction struct {
+	Class      string           `json:"_class"`
+	Parameters []BuildParameter `json:"parameters"`
+	Causes     []BuildCause     `json:"causes"`
+}
+
+// BuildParameter a parameter of a build. The value is missing for password parameters
+type BuildParameter struct {
+	Class string      `json:"_class"`
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+// BuildCause the reason a build was started
+type BuildCause struct {
+	ShortDescription string `json:"shortDescription"`
+	UserID           string `json:"userId"`
+	UserName         string `json:"userName"`
+}
+
+// Indexing the branch indexing of a multi branch project
+type Indexing struct {
+	Building  bool   `json:"building"`
+	Result    string `json:"result"`
+	Timestamp int64  `json:"timestamp"`
+	Duration  int64  `json:"duration"`
+}
+
+// PipelineRun a pipeline build as returned by the pipeline REST API
+type PipelineRun struct {
+	ID              string          `json:"id"`
+	Name            string          `json:"name"`
+	Status          string          `json:"status"`
+	StartTimeMillis int64           `json:"startTimeMillis"`
+	DurationMillis  int64           `json:"durationMillis"`
+	Stages          []PipelineStage `json:"stages"`
+}
+
+// PipelineStage a stage of a pipeline build as returned by the pipeline REST API
+type PipelineStage struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Status          string `json:"status"`
+	StartTimeMillis int64  `json:"startTimeMillis"`
+	DurationMillis  int64  `json:"durationMillis"`
+}
+
+// CurrentStage returns the stage which is currently running or the last stage if none are running
+func (r *PipelineRun) CurrentStage() *PipelineStage {
+	for i := range r.Stages {
+		switch r.Stages[i].Status {
+		case "IN_PROGRESS", "PAUSED_PENDING_INPUT":
+			return &r.Stages[i]
+		}
+	}
+	if len(r.Stages) > 0 {
+		return &r.Stages[len(r.Stages)-1]
+	}
+	return nil
+}
+
+// GetJobByFullName returns the job for the given full name such as 'owner/repo/branch'
+func GetJobByFullName(jenkins gojenkins.JenkinsClient, fullName string) (gojenkins.Job, error) {
+	job, err := jenkins.GetJobByPath(strings.Split(strings.Trim(fullName, "/"), "/")...)
+	if err != nil {
+		return job, errors.Wrapf(err, "cannot find job %s", fullName)
+	}
+	return job, nil
+}
+
+type client struct {
+	*gojenkins.Jenkins
+
+	auth       *gojenkins.Auth
+	httpClient *http.Client
+}
+
+var _ Client = (*client)(nil)
+
+// NewClient creates a new Jenkins client for the given base URL using the given HTTP client
+func NewClient(auth *gojenkins.Auth, baseURL string, httpClient *http.Client) Client {
+	jenkins := gojenkins.NewJenkins(auth, baseURL)
+	jenkins.SetHTTPClient(httpClient)
+	return &client{
+		Jenkins:    jenkins,
+		auth:       auth,
+		httpClient: httpClient,
+	}
+}
+
+// SetHTTPClient sets the HTTP client used by both the underlying client and the extended operations
+func (c *client) SetHTTPClient(httpClient *http.Client) {
+	c.Jenkins.SetHTTPClient(httpClient)
+	c.httpClient = httpClient
+}
+
+// BuildWithParameters triggers a build of the given job
+func (c *client) BuildWithParameters(job gojenkins.Job, params url.Values) (string, error) {
+	path := "build"
+	if len(params) > 0 {
+		path = "buildWithParameters"
+	}
+	resp, err := c.post(util.UrlJoin(c.jobURL(job), path), params)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", errors.Errorf("no queue item location returned when triggering %s", job.FullName)
+	}
+	return location, nil
+}
+
+// GetQueueItem returns the queue item for the given queue item URL
+func (c *client) GetQueueItem(queueItemURL string) (*QueueItem, error) {
+	item := &QueueItem{}
+	u := util.UrlJoin(c.switchBaseURL(queueItemURL), "api/json")
+	err := c.getJSON(u, item)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get queue item %s", queueItemURL)
+	}
+	return item, nil
+}
+
+// GetBuildDetails returns the details of the build which are not included in the gojenkins build
+func (c *client) GetBuildDetails(buildURL string) (*BuildDetails, error) {
+	details := &BuildDetails{}
+	err := c.getJSON(util.UrlJoin(c.switchBaseURL(buildURL), "api/json"), details)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get build %s", buildURL)
+	}
+	return details, nil
+}
+
+// GetPipelineRun returns the stages of a pipeline build via the pipeline REST API
+func (c *client) GetPipelineRun(buildURL string) (*PipelineRun, error) {
+	run := &PipelineRun{}
+	err := c.getJSON(util.UrlJoin(c.switchBaseURL(buildURL), "wfapi/describe"), run)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the pipeline stages of %s", buildURL)
+	}
+	return run, nil
+}
+
+// GetBuilds returns the recent builds of the given job
+func (c *client) GetBuilds(job gojenkins.Job) ([]gojenkins.Build, error) {
+	result := struct {
+		Builds []gojenkins.Build `json:"builds"`
+	}{}
+	u := util.UrlJoin(c.jobURL(job), "api/json") + "?tree=builds[id,number,url,building,result,duration,timestamp]"
+	err := c.getJSON(u, &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the builds of %s", job.FullName)
+	}
+	return result.Builds, nil
+}
+
+// GetJobQueueItems returns the items in the build queue for the given job
+func (c *client) GetJobQueueItems(job gojenkins.Job) ([]QueueItem, error) {
+	result := struct {
+		Items []QueueItem `json:"items"`
+	}{}
+	err := c.getJSON(util.UrlJoin(c.BaseURL(), "queue/api/json"), &result)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the build queue")
+	}
+	jobURL := strings.TrimSuffix(c.jobURL(job), "/")
+	var answer []QueueItem
+	for _, item := range result.Items {
+		if item.Task != nil && strings.TrimSuffix(c.switchBaseURL(item.Task.URL), "/") == jobURL {
+			answer = append(answer, item)
+		}
+	}
+	return answer, nil
+}
+
+// CancelQueueItem removes the item with the given id from the build queue
+func (c *client) CancelQueueItem(id int) error {
+	u := util.UrlJoin(c.BaseURL(), "queue/cancelItem") + fmt.Sprintf("?id=%d", id)
+	resp, err := c.post(u, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to cancel queue item %d", id)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// GetJobConfigXML returns the config.xml of the given job
+func (c *client) GetJobConfigXML(job gojenkins.Job) (string, error) {
+	text, err := c.getText(util.UrlJoin(c.jobURL(job), "config.xml"))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the config.xml of %s", job.FullName)
+	}
+	return text, nil
+}
+
+// UpdateJobWithXML replaces the config.xml of the given job
+func (c *client) UpdateJobWithXML(job gojenkins.Job, jobXML string) error {
+	u := util.UrlJoin(c.jobURL(job), "config.xml")
+	req, err := http.NewRequest(http.MethodPost, u, strings.NewReader(jobXML))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create request for %s", u)
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	resp, err := c.do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the config.xml of %s", job.FullName)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// GetIndexing returns the last branch indexing of the given multi branch project
+func (c *client) GetIndexing(job gojenkins.Job) (*Indexing, error) {
+	indexing := &Indexing{}
+	err := c.getJSON(util.UrlJoin(c.jobURL(job), "indexing/api/json"), indexing)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the branch indexing of %s", job.FullName)
+	}
+	return indexing, nil
+}
+
+// GetIndexingLog returns the log of the last branch indexing of the given multi branch project
+func (c *client) GetIndexingLog(job gojenkins.Job) (string, error) {
+	text, err := c.getText(util.UrlJoin(c.jobURL(job), "indexing/consoleText"))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the branch indexing log of %s", job.FullName)
+	}
+	return text, nil
+}
+
+// DownloadArtifact writes the archived artifact of the given build to out returning the number of bytes written
+func (c *client) DownloadArtifact(build gojenkins.Build, artifact gojenkins.Artifact, out io.Writer) (int64, error) {
+	u := util.UrlJoin(c.switchBaseURL(build.Url), "artifact", escapePath(artifact.RelativePath))
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to create request for %s", u)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to download artifact %s", artifact.RelativePath)
+	}
+	defer resp.Body.Close()
+	size, err := io.Copy(out, resp.Body)
+	if err != nil {
+		return size, errors.Wrapf(err, "failed to download artifact %s", artifact.RelativePath)
+	}
+	if resp.ContentLength >= 0 && size != resp.ContentLength {
+		return size, errors.Errorf("downloaded %d bytes of artifact %s but expected %d", size, artifact.RelativePath, resp.ContentLength)
+	}
+	return size, nil
+}
+
+// escapePath escapes each segment of the relative path for use in a URL
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// switchBaseURL returns the URL using the base URL of this client
+func (c *client) switchBaseURL(u string) string {
+	return jenkins.SwitchJenkinsBaseURL(u, c.BaseURL())
+}
+
+// jobURL returns the URL of the job using the base URL of this client as Jenkins may not
+// know the URL we are using to connect to it
+func (c *client) jobURL(job gojenkins.Job) string {
+	if job.Url == "" {
+		return util.UrlJoin(c.BaseURL(), gojenkins.FullJobPath(strings.Split(job.FullName, "/")...))
+	}
+	return c.switchBaseURL(job.Url)
+}
+
+func (c *client) getJSON(u string, result interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create request for %s", u)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (c *client) getText(u string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create request for %s", u)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read %s", u)
+	}
+	return string(data), nil
+}
+
+func (c *client) post(u string, params url.Values) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, u, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create request for %s", u)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(req)
+}
+
+// do sends the request returning an error for any non successful status code. The error text
+// starts with the status code like the errors returned by the underlying client
+func (c *client) do(req *http.Request) (*http.Response, error) {
+	if c.auth != nil {
+		if c.auth.BearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+c.auth.BearerToken)
+		} else if c.auth.Username != "" {
+			req.SetBasicAuth(c.auth.Username, c.auth.ApiToken)
+		}
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, errors.New(resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,110 @@
+package jenkinsutil_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQueueServer a fake Jenkins which queues builds and reports the state of its queue items
+type fakeQueueServer struct {
+	params url.Values
+	items  map[string]string
+}
+
+func (s *fakeQueueServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/job/myjob/buildWithParameters":
+		err := r.ParseForm()
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.params = r.PostForm
+		w.Header().Set("Location", "http://"+r.Host+"/queue/item/5/")
+		w.WriteHeader(http.StatusCreated)
+
+	case "/job/nolocation/build":
+		w.WriteHeader(http.StatusCreated)
+
+	default:
+		item, ok := s.items[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, item)
+	}
+}
+
+func newQueueClient(t *testing.T, s *fakeQueueServer) (jenkinsutil.Client, *httptest.Server) {
+	server := httptest.NewServer(s)
+	jsvc := &jenkinsutil.JenkinsServer{Name: "test", URL: server.URL}
+	client, err := jsvc.CreateClient()
+	require.NoError(t, err, "failed to create client")
+	return client, server
+}
+
+func TestBuildWithParametersReturnsQueueItemLocation(t *testing.T) {
+	s := &fakeQueueServer{}
+	client, server := newQueueClient(t, s)
+	defer server.Close()
+
+	job := gojenkins.Job{Name: "myjob", FullName: "myjob", Url: server.URL + "/job/myjob/"}
+	params := url.Values{}
+	params.Set("VERSION", "1.2.3")
+	queueItemURL, err := client.BuildWithParameters(job, params)
+	require.NoError(t, err, "failed to trigger build")
+	assert.Equal(t, server.URL+"/queue/item/5/", queueItemURL, "queue item URL")
+	assert.Equal(t, "1.2.3", s.params.Get("VERSION"), "posted VERSION parameter")
+}
+
+func TestBuildWithParametersWithoutLocation(t *testing.T) {
+	s := &fakeQueueServer{}
+	client, server := newQueueClient(t, s)
+	defer server.Close()
+
+	job := gojenkins.Job{Name: "nolocation", FullName: "nolocation", Url: server.URL + "/job/nolocation/"}
+	_, err := client.BuildWithParameters(job, nil)
+	require.Error(t, err, "should fail when no queue item location is returned")
+	assert.Contains(t, err.Error(), "no queue item location returned when triggering nolocation")
+}
+
+func TestGetQueueItem(t *testing.T) {
+	s := &fakeQueueServer{
+		items: map[string]string{
+			"/queue/item/5/api/json": `{"id":5,"why":"Waiting for next available executor","buildable":true,"executable":null}`,
+			"/queue/item/6/api/json": `{"id":6,"cancelled":true,"executable":null}`,
+			"/queue/item/7/api/json": `{"id":7,"executable":{"number":12,"url":"http://jenkins/job/myjob/12/"}}`,
+		},
+	}
+	client, server := newQueueClient(t, s)
+	defer server.Close()
+
+	item, err := client.GetQueueItem(server.URL + "/queue/item/5/")
+	require.NoError(t, err, "failed to get the waiting queue item")
+	assert.Equal(t, 5, item.ID, "waiting queue item ID")
+	assert.Nil(t, item.Executable, "waiting queue item should have no executable")
+	assert.False(t, item.Cancelled, "waiting queue item should not be cancelled")
+	assert.Equal(t, "Waiting for next available executor", item.Why, "waiting queue item reason")
+
+	item, err = client.GetQueueItem(server.URL + "/queue/item/6/")
+	require.NoError(t, err, "failed to get the cancelled queue item")
+	assert.True(t, item.Cancelled, "queue item should be cancelled")
+	assert.Nil(t, item.Executable, "cancelled queue item should have no executable")
+
+	item, err = client.GetQueueItem(server.URL + "/queue/item/7/")
+	require.NoError(t, err, "failed to get the started queue item")
+	require.NotNil(t, item.Executable, "started queue item should have an executable")
+	assert.Equal(t, 12, item.Executable.Number, "build number")
+
+	_, err = client.GetQueueItem(server.URL + "/queue/item/8/")
+	require.Error(t, err, "should fail for a missing queue item")
+}
@@ -0,0 +1,69 @@
+package jenkinsutil
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// JobTemplateData the data passed into a job or folder XML template
+type JobTemplateData struct {
+	// GitURL the URL of the git repository
+	GitURL string
+
+	// CredentialsID the id of the Jenkins credentials used to clone the git repository if any
+	CredentialsID string
+
+	// Branch the git branch
+	Branch string
+
+	// Jenkinsfile the path of the Jenkinsfile in the git repository
+	Jenkinsfile string
+
+	// Owner the owner of the git repository
+	Owner string
+
+	// Repo the name of the git repository
+	Repo string
+
+	// JobName the name of the job or folder being created
+	JobName string
+
+	// FullName the full path of the job or folder being created such as 'owner/repo/branch'
+	FullName string
+
+	// URL the URL of the job or folder being created
+	URL string
+}
+
+// RenderJobTemplate renders the Go template file to create the XML of a job or folder. The xml function
+// can be used in the template to escape values
+func RenderJobTemplate(path string, data *JobTemplateData) (string, error) {
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to load job template %s", path)
+	}
+	funcs := template.FuncMap{
+		"xml": xmlEscape,
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(funcs).Parse(string(text))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse job template %s", path)
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to render job template %s", path)
+	}
+	return buf.String(), nil
+}
+
+func xmlEscape(text string) (string, error) {
+	var buf bytes.Buffer
+	err := xml.EscapeText(&buf, []byte(text))
+	return buf.String(), err
+}
@@ -0,0 +1,37 @@
+package jenkinsutil_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderJobTemplate(t *testing.T) {
+	data := &jenkinsutil.JobTemplateData{
+		GitURL:      "https://github.com/myowner/myrepo.git",
+		Branch:      "feature&fix",
+		Jenkinsfile: "Jenkinsfile",
+		Owner:       "myowner",
+		Repo:        "myrepo",
+		JobName:     "master",
+		FullName:    "myowner/myrepo/master",
+	}
+	text, err := jenkinsutil.RenderJobTemplate(filepath.Join("test_data", "templates", "job.xml.tmpl"), data)
+	require.NoError(t, err, "failed to render template")
+
+	assert.True(t, strings.Contains(text, "<numToKeep>20</numToKeep>"), "should contain the build discarder")
+	assert.True(t, strings.Contains(text, "<description>myowner/myrepo master</description>"), "should contain the description")
+
+	config, err := jenkinsutil.ParsePipelineConfig(text)
+	require.NoError(t, err, "failed to parse the rendered pipeline")
+	assert.Equal(t, "https://github.com/myowner/myrepo.git", config.GitURL, "git URL")
+	assert.Equal(t, "*/feature&fix", config.Branch, "escaped branch")
+	assert.Equal(t, "Jenkinsfile", config.ScriptPath, "Jenkinsfile")
+
+	_, err = jenkinsutil.RenderJobTemplate(filepath.Join("test_data", "templates", "does-not-exist.xml.tmpl"), data)
+	assert.Error(t, err, "should fail for a missing template")
+}
@@ -0,0 +1,191 @@
+package jenkinsutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// ConsoleNotesStrip removes the hidden ConsoleNote annotations from the build log
+	ConsoleNotesStrip = "strip"
+
+	// ConsoleNotesKeep leaves the hidden ConsoleNote annotations in the build log
+	ConsoleNotesKeep = "keep"
+
+	// ConsoleNotesDecode replaces the hidden ConsoleNote annotations with their class and any text they contain
+	ConsoleNotesDecode = "decode"
+
+	// LogTimestampFormat the format of the timestamps added to the lines of a build log
+	LogTimestampFormat = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// ConsoleNotesModes the supported ways of handling ConsoleNote annotations
+var ConsoleNotesModes = []string{ConsoleNotesStrip, ConsoleNotesKeep, ConsoleNotesDecode}
+
+var (
+	// consoleNoteRegex matches the ConsoleNote annotations Jenkins hides in the build log using the ANSI conceal code
+	consoleNoteRegex = regexp.MustCompile("\x1b\\[8mha:([A-Za-z0-9+/=]*)\x1b\\[0m")
+
+	ansiRegex = regexp.MustCompile("\x1b\\[[0-9;?]*[ -/]*[@-~]")
+)
+
+// ChainLineFilters returns a filter which passes each line through the filters in order skipping the line if
+// any filter skips it
+func ChainLineFilters(filters ...LineFilter) LineFilter {
+	return func(line string) (string, bool) {
+		for _, filter := range filters {
+			var ok bool
+			line, ok = filter(line)
+			if !ok {
+				return "", false
+			}
+		}
+		return line, true
+	}
+}
+
+// ConsoleNotesFilter returns the filter which strips or decodes the ConsoleNote annotations in each line of
+// a build log or nil if they should be kept
+func ConsoleNotesFilter(mode string) LineFilter {
+	switch mode {
+	case ConsoleNotesStrip:
+		return func(line string) (string, bool) {
+			return consoleNoteRegex.ReplaceAllString(line, ""), true
+		}
+	case ConsoleNotesDecode:
+		return func(line string) (string, bool) {
+			return consoleNoteRegex.ReplaceAllStringFunc(line, func(note string) string {
+				match := consoleNoteRegex.FindStringSubmatch(note)
+				return DecodeConsoleNote(match[1])
+			}), true
+		}
+	default:
+		return nil
+	}
+}
+
+// StripANSIFilter removes any ANSI escape codes such as colours from the line
+func StripANSIFilter(line string) (string, bool) {
+	return ansiRegex.ReplaceAllString(line, ""), true
+}
+
+// PrefixFilter returns the filter which prefixes each line with the time it was received if timestamps is true
+// followed by the given prefix
+func PrefixFilter(prefix string, timestamps bool, now func() time.Time) LineFilter {
+	return func(line string) (string, bool) {
+		if timestamps {
+			return now().Format(LogTimestampFormat) + " " + prefix + line, true
+		}
+		return prefix + line, true
+	}
+}
+
+// DecodeConsoleNote returns a readable description of the base64 encoded ConsoleNote such as
+// '[HyperlinkNote /job/foo/]' or an empty string if it cannot be decoded
+func DecodeConsoleNote(encoded string) string {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return ""
+	}
+	serialized, err := consoleNoteData(data)
+	if err != nil {
+		return ""
+	}
+	className, texts := parseSerializedNote(serialized)
+	if className == "" {
+		return ""
+	}
+	if i := strings.LastIndex(className, "."); i >= 0 {
+		className = className[i+1:]
+	}
+	return "[" + strings.Join(append([]string{className}, texts...), " ") + "]"
+}
+
+// consoleNoteData returns the serialized note skipping the MAC Jenkins adds before the gzipped data
+func consoleNoteData(data []byte) ([]byte, error) {
+	r := bytes.NewReader(data)
+	var size int32
+	err := binary.Read(r, binary.BigEndian, &size)
+	if err != nil {
+		return nil, err
+	}
+	if size < 0 {
+		_, err = r.Seek(int64(-size), 1)
+		if err != nil {
+			return nil, err
+		}
+		err = binary.Read(r, binary.BigEndian, &size)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if size < 0 || int64(size) > int64(r.Len()) {
+		return nil, fmt.Errorf("invalid console note size %d", size)
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return ioutil.ReadAll(gz)
+}
+
+const (
+	javaStreamMagic = 0xACED
+	javaClassDesc   = 0x72
+	javaString      = 0x74
+)
+
+// parseSerializedNote returns the class name of the Java serialized note and any text values it contains
+func parseSerializedNote(data []byte) (string, []string) {
+	if len(data) < 4 || binary.BigEndian.Uint16(data) != javaStreamMagic {
+		return "", nil
+	}
+	className := ""
+	var texts []string
+	for i := 4; i+3 <= len(data); i++ {
+		if data[i] != javaClassDesc && data[i] != javaString {
+			continue
+		}
+		size := int(binary.BigEndian.Uint16(data[i+1:]))
+		end := i + 3 + size
+		if size == 0 || end > len(data) {
+			continue
+		}
+		text := string(data[i+3 : end])
+		if !isPrintable(text) {
+			continue
+		}
+		if data[i] == javaClassDesc {
+			if className == "" {
+				className = text
+			}
+		} else if !isTypeSignature(text) {
+			texts = append(texts, text)
+		}
+		i = end - 1
+	}
+	return className, texts
+}
+
+// isTypeSignature returns true if the text is the JVM type of a field such as 'Ljava/lang/String;'
+func isTypeSignature(text string) bool {
+	return (strings.HasPrefix(text, "L") || strings.HasPrefix(text, "[")) && strings.HasSuffix(text, ";")
+}
+
+func isPrintable(text string) bool {
+	for _, r := range text {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
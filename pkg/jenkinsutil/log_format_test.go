@@ -0,0 +1,83 @@
+package jenkinsutil_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeConsoleNote encodes a serialized note the same way as Jenkins including a MAC
+func encodeConsoleNote(t *testing.T, className string, texts ...string) string {
+	var serialized bytes.Buffer
+	serialized.Write([]byte{0xAC, 0xED, 0x00, 0x05, 0x73})
+	writeJavaString(&serialized, 0x72, className)
+	serialized.Write([]byte{0x00, 0x00, 0x00, 0x01, 0x02, 0x00, 0x01, 0x4C, 0x00, 0x03})
+	serialized.WriteString("url")
+	writeJavaString(&serialized, 0x74, "Ljava/lang/String;")
+	serialized.Write([]byte{0x78, 0x70})
+	for _, text := range texts {
+		writeJavaString(&serialized, 0x74, text)
+	}
+
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	_, err := gz.Write(serialized.Bytes())
+	require.NoError(t, err, "failed to gzip note")
+	require.NoError(t, gz.Close(), "failed to gzip note")
+
+	var data bytes.Buffer
+	mac := make([]byte, 32)
+	require.NoError(t, binary.Write(&data, binary.BigEndian, int32(-len(mac))), "failed to write MAC size")
+	data.Write(mac)
+	require.NoError(t, binary.Write(&data, binary.BigEndian, int32(gzipped.Len())), "failed to write note size")
+	data.Write(gzipped.Bytes())
+	return "\x1b[8mha:" + base64.StdEncoding.EncodeToString(data.Bytes()) + "\x1b[0m"
+}
+
+func writeJavaString(buf *bytes.Buffer, code byte, text string) {
+	buf.WriteByte(code)
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(text)))
+	buf.WriteString(text)
+}
+
+func TestConsoleNotesFilter(t *testing.T) {
+	note := encodeConsoleNote(t, "hudson.console.HyperlinkNote", "/job/other/")
+	line := "Starting building: " + note + "other #1"
+
+	keep := jenkinsutil.ConsoleNotesFilter(jenkinsutil.ConsoleNotesKeep)
+	assert.Nil(t, keep, "keep should not need a filter")
+
+	text, ok := jenkinsutil.ConsoleNotesFilter(jenkinsutil.ConsoleNotesStrip)(line)
+	assert.True(t, ok, "should keep the line")
+	assert.Equal(t, "Starting building: other #1", text, "stripped line")
+
+	text, ok = jenkinsutil.ConsoleNotesFilter(jenkinsutil.ConsoleNotesDecode)(line)
+	assert.True(t, ok, "should keep the line")
+	assert.Equal(t, "Starting building: [HyperlinkNote /job/other/]other #1", text, "decoded line")
+
+	text, _ = jenkinsutil.ConsoleNotesFilter(jenkinsutil.ConsoleNotesDecode)("\x1b[8mha:bm90IGEgbm90ZQ==\x1b[0mplain")
+	assert.Equal(t, "plain", text, "notes which cannot be decoded should be removed")
+}
+
+func TestStripANSIAndPrefixFilters(t *testing.T) {
+	now := func() time.Time {
+		return time.Date(2020, 3, 4, 10, 11, 12, 13000000, time.UTC)
+	}
+	filter := jenkinsutil.ChainLineFilters(
+		jenkinsutil.StripANSIFilter,
+		jenkinsutil.PrefixFilter("[myrepo/master#3] ", true, now),
+	)
+	text, ok := filter("\x1b[1;31mERROR:\x1b[0m tests failed")
+	assert.True(t, ok, "should keep the line")
+	assert.Equal(t, "2020-03-04T10:11:12.013Z [myrepo/master#3] ERROR: tests failed", text, "formatted line")
+
+	text, _ = jenkinsutil.PrefixFilter("[a#1] ", false, now)("hello")
+	assert.Equal(t, "[a#1] hello", text, "prefixed line without timestamp")
+}
@@ -0,0 +1,78 @@
+package jenkinsutil
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// LineFilter returns the text to write for a line of a build log or false if the line should be skipped
+type LineFilter func(line string) (string, bool)
+
+// lineWriter writes each complete line of a build log through a filter. Any incomplete last line is written
+// when the writer is closed
+type lineWriter struct {
+	out     io.Writer
+	filter  LineFilter
+	partial []byte
+}
+
+// NewLineWriter creates a writer which writes each line of the build log through the filter to the output.
+// The writer must be closed to write any incomplete last line
+func NewLineWriter(out io.Writer, filter LineFilter) io.WriteCloser {
+	return &lineWriter{out: out, filter: filter}
+}
+
+// Write implements io.Writer
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(w.partial[:i])
+		w.partial = w.partial[i+1:]
+		err := w.writeLine(line)
+		if err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+// Close writes any incomplete last line
+func (w *lineWriter) Close() error {
+	if len(w.partial) == 0 {
+		return nil
+	}
+	line := string(w.partial)
+	w.partial = nil
+	return w.writeLine(line)
+}
+
+func (w *lineWriter) writeLine(line string) error {
+	text, ok := w.filter(strings.TrimSuffix(line, "\r"))
+	if !ok {
+		return nil
+	}
+	_, err := io.WriteString(w.out, text+"\n")
+	return err
+}
+
+var stageStartRegex = regexp.MustCompile(`^\[Pipeline\] \{ \(.*\)$`)
+
+// CondensedLogFilter only keeps the lines of a pipeline build log which show the stages which start along with
+// any errors, warnings and the final result of the build
+func CondensedLogFilter(line string) (string, bool) {
+	if stageStartRegex.MatchString(line) {
+		return line, true
+	}
+	for _, prefix := range []string{"ERROR:", "WARNING:", "Finished:"} {
+		if strings.HasPrefix(line, prefix) {
+			return line, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,33 @@
+package jenkinsutil_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCondensedLogWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := jenkinsutil.NewLineWriter(&buf, jenkinsutil.CondensedLogFilter)
+
+	// lets write the log in chunks which split lines like the tailed log
+	chunks := []string{
+		"Started by user admin\n[Pipeline] Start of Pipeline\n[Pipe",
+		"line] { (Build)\r\n+ make build\ncompiling\n",
+		"WARNING: deprecated flag\n[Pipeline] { (Test)\nERROR: tests failed\n",
+		"Finished: FAILURE",
+	}
+	for _, chunk := range chunks {
+		_, err := io.WriteString(w, chunk)
+		require.NoError(t, err, "failed to write %s", chunk)
+	}
+	assert.Equal(t, "[Pipeline] { (Build)\nWARNING: deprecated flag\n[Pipeline] { (Test)\nERROR: tests failed\n", buf.String(), "condensed log before close")
+
+	err := w.Close()
+	require.NoError(t, err, "failed to close")
+	assert.Equal(t, "[Pipeline] { (Build)\nWARNING: deprecated flag\n[Pipeline] { (Test)\nERROR: tests failed\nFinished: FAILURE\n", buf.String(), "condensed log")
+}
@@ -0,0 +1,69 @@
+package jenkinsutil
+
+import (
+	"bytes"
+	"text/template"
+)
+
+const (
+	// MultiBranchProjectClass the class of a multi branch pipeline project
+	MultiBranchProjectClass = "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject"
+
+	// OrganizationFolderClass the class of an organization folder such as a GitHub or Bitbucket organization
+	OrganizationFolderClass = "jenkins.branch.OrganizationFolder"
+)
+
+var multiBranchProjectTemplate = template.Must(template.New("multibranch").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xml version='1.1' encoding='UTF-8'?>
+<org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject plugin="workflow-multibranch">
+  <properties/>
+  <folderViews class="jenkins.branch.MultiBranchProjectViewHolder" plugin="branch-api">
+    <owner class="org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject" reference="../.."/>
+  </folderViews>
+  <healthMetrics/>
+  <icon class="jenkins.branch.MetadataActionFolderIcon" plugin="branch-api">
+    <owner class="org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject" reference="../.."/>
+  </icon>
+  <orphanedItemStrategy class="com.cloudbees.hudson.plugins.folder.computed.DefaultOrphanedItemStrategy" plugin="cloudbees-folder">
+    <pruneDeadBranches>true</pruneDeadBranches>
+    <daysToKeep>-1</daysToKeep>
+    <numToKeep>-1</numToKeep>
+  </orphanedItemStrategy>
+  <triggers/>
+  <disabled>false</disabled>
+  <sources class="jenkins.branch.MultiBranchProject$BranchSourceList" plugin="branch-api">
+    <data>
+      <jenkins.branch.BranchSource>
+        <source class="jenkins.plugins.git.GitSCMSource" plugin="git">
+          <id>{{ xml .GitURL }}</id>
+          <remote>{{ xml .GitURL }}</remote>
+          <credentialsId>{{ xml .CredentialsID }}</credentialsId>
+          <traits>
+            <jenkins.plugins.git.traits.BranchDiscoveryTrait/>
+          </traits>
+        </source>
+        <strategy class="jenkins.branch.DefaultBranchPropertyStrategy">
+          <properties class="empty-list"/>
+        </strategy>
+      </jenkins.branch.BranchSource>
+    </data>
+    <owner class="org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject" reference="../.."/>
+  </sources>
+  <factory class="org.jenkinsci.plugins.workflow.multibranch.WorkflowBranchProjectFactory">
+    <owner class="org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject" reference="../.."/>
+    <scriptPath>{{ xml .Jenkinsfile }}</scriptPath>
+  </factory>
+</org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject>
+`))
+
+// CreateMultiBranchProjectXML returns the XML to create a multi branch project which discovers the branches
+// of the git repository using the given Jenkins credentials and builds them using the given Jenkinsfile
+func CreateMultiBranchProjectXML(gitURL string, credentialsID string, jenkinsfile string) (string, error) {
+	data := &JobTemplateData{
+		GitURL:        gitURL,
+		CredentialsID: credentialsID,
+		Jenkinsfile:   jenkinsfile,
+	}
+	var buf bytes.Buffer
+	err := multiBranchProjectTemplate.Execute(&buf, data)
+	return buf.String(), err
+}
@@ -0,0 +1,20 @@
+package jenkinsutil_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateMultiBranchProjectXML(t *testing.T) {
+	text, err := jenkinsutil.CreateMultiBranchProjectXML("https://github.com/myowner/myrepo.git", "git-creds", "ci/Jenkinsfile&more")
+	require.NoError(t, err, "should not have failed")
+
+	assert.Contains(t, text, "<remote>https://github.com/myowner/myrepo.git</remote>", "git URL")
+	assert.Contains(t, text, "<credentialsId>git-creds</credentialsId>", "credentials")
+	assert.Contains(t, text, "<scriptPath>ci/Jenkinsfile&amp;more</scriptPath>", "escaped Jenkinsfile")
+	assert.True(t, strings.Contains(text, "<"+jenkinsutil.MultiBranchProjectClass), "root element")
+}
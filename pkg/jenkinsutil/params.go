@@ -0,0 +1,179 @@
+package jenkinsutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/magiconair/properties"
+	"github.com/pkg/errors"
+)
+
+// BuildParameters build parameters which can be unmarshalled from a YAML or JSON object using the
+// same rules as a YAML or JSON build parameters file
+type BuildParameters url.Values
+
+// UnmarshalJSON implements json.Unmarshaler
+func (p *BuildParameters) UnmarshalJSON(data []byte) error {
+	values, err := parseJSONParameters(data)
+	if err != nil {
+		return err
+	}
+	*p = BuildParameters(values)
+	return nil
+}
+
+// ParseBuildParameters parses the given KEY=VALUE expressions into build parameters.
+// Repeating a key adds another value so that multi value parameters can be passed and KEY=
+// passes an empty value
+func ParseBuildParameters(params []string) (url.Values, error) {
+	values := url.Values{}
+	for _, param := range params {
+		i := strings.Index(param, "=")
+		if i < 0 {
+			return values, fmt.Errorf("invalid build parameter '%s' should be of the form KEY=VALUE", param)
+		}
+		key := strings.TrimSpace(param[0:i])
+		if key == "" {
+			return values, fmt.Errorf("invalid build parameter '%s' has no key", param)
+		}
+		values.Add(key, param[i+1:])
+	}
+	return values, nil
+}
+
+// LoadBuildParametersFile loads the build parameters from a YAML, JSON or Java properties file
+// depending on the file extension. Any ${NAME} expressions in the values are replaced with
+// the value of the environment variable
+func LoadBuildParametersFile(path string) (url.Values, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load build parameters file %s", path)
+	}
+	var values url.Values
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".properties":
+		values, err = parsePropertiesParameters(data)
+	case ".yaml", ".yml", ".json":
+		values, err = parseYAMLParameters(data)
+	default:
+		return nil, fmt.Errorf("unsupported build parameters file %s. Supported extensions are .yaml, .yml, .json and .properties", path)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse build parameters file %s", path)
+	}
+	return ExpandBuildParameters(values), nil
+}
+
+// ExpandBuildParameters returns a copy of the build parameters with any ${NAME} expressions in the values
+// replaced with the value of the environment variable
+func ExpandBuildParameters(values url.Values) url.Values {
+	answer := url.Values{}
+	for k, v := range values {
+		expanded := make([]string, len(v))
+		for i := range v {
+			expanded[i] = ExpandEnvVars(v[i])
+		}
+		answer[k] = expanded
+	}
+	return answer
+}
+
+var envVarRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ExpandEnvVars replaces any ${NAME} expressions in the text with the value of the environment variable. Any other
+// use of $ such as $NAME is left as is
+func ExpandEnvVars(text string) string {
+	return envVarRegex.ReplaceAllStringFunc(text, func(expression string) string {
+		return os.Getenv(envVarRegex.FindStringSubmatch(expression)[1])
+	})
+}
+
+// MergeBuildParameters returns the build parameters with any keys in overrides replacing the
+// values in the base parameters
+func MergeBuildParameters(base url.Values, overrides url.Values) url.Values {
+	answer := url.Values{}
+	for k, v := range base {
+		answer[k] = v
+	}
+	for k, v := range overrides {
+		answer[k] = v
+	}
+	return answer
+}
+
+func parsePropertiesParameters(data []byte) (url.Values, error) {
+	// we expand environment variables ourselves so that all file formats behave the same
+	loader := &properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+	p, err := loader.LoadBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	values := url.Values{}
+	for k, v := range p.Map() {
+		values.Set(k, v)
+	}
+	return values, nil
+}
+
+func parseYAMLParameters(data []byte) (url.Values, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONParameters(jsonData)
+}
+
+func parseJSONParameters(data []byte) (url.Values, error) {
+	m := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&m)
+	if err != nil {
+		return nil, err
+	}
+	values := url.Values{}
+	for k, v := range m {
+		switch t := v.(type) {
+		case []interface{}:
+			values[k] = []string{}
+			for _, e := range t {
+				s, err := parameterValue(k, e)
+				if err != nil {
+					return nil, err
+				}
+				values.Add(k, s)
+			}
+		default:
+			s, err := parameterValue(k, v)
+			if err != nil {
+				return nil, err
+			}
+			values.Set(k, s)
+		}
+	}
+	return values, nil
+}
+
+func parameterValue(key string, value interface{}) (string, error) {
+	switch t := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case json.Number, bool:
+		return fmt.Sprintf("%v", t), nil
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("the value of build parameter %s should be a string, number, boolean or a list of them", key)
+	}
+}
@@ -0,0 +1,64 @@
+package jenkinsutil_test
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBuildParameters(t *testing.T) {
+	values, err := jenkinsutil.ParseBuildParameters([]string{"VERSION=1.2.3", "EMPTY=", "TARGET=linux", "TARGET=darwin", "EXPR=a=b"})
+	require.NoError(t, err, "should not have failed")
+
+	expected := url.Values{
+		"VERSION": []string{"1.2.3"},
+		"EMPTY":   []string{""},
+		"TARGET":  []string{"linux", "darwin"},
+		"EXPR":    []string{"a=b"},
+	}
+	assert.Equal(t, expected, values, "parsed parameters")
+}
+
+func TestParseBuildParametersInvalid(t *testing.T) {
+	for _, param := range []string{"NOVALUE", "=value"} {
+		_, err := jenkinsutil.ParseBuildParameters([]string{param})
+		assert.Error(t, err, "should have failed to parse %s", param)
+	}
+}
+
+func TestLoadBuildParametersFile(t *testing.T) {
+	err := os.Setenv("TP_TEST_REGISTRY", "gcr.io/myproject")
+	require.NoError(t, err, "failed to set environment variable")
+	defer os.Unsetenv("TP_TEST_REGISTRY")
+
+	for _, name := range []string{"params.yaml", "params.json", "params.properties"} {
+		path := filepath.Join("test_data", "params", name)
+		values, err := jenkinsutil.LoadBuildParametersFile(path)
+		require.NoError(t, err, "failed to load %s", path)
+
+		assert.Equal(t, "1.2.3", values.Get("VERSION"), "VERSION in %s", name)
+		assert.Equal(t, "1000000", values.Get("COUNT"), "COUNT in %s", name)
+		assert.Equal(t, "true", values.Get("DEBUG"), "DEBUG in %s", name)
+		assert.Equal(t, []string{""}, values["EMPTY"], "EMPTY in %s", name)
+		assert.Equal(t, "gcr.io/myproject/app", values.Get("IMAGE"), "IMAGE in %s", name)
+		assert.Equal(t, "pa$$word", values.Get("PASSWORD"), "PASSWORD in %s", name)
+		assert.Equal(t, "$TP_TEST_REGISTRY/app", values.Get("LITERAL"), "LITERAL in %s", name)
+		if name != "params.properties" {
+			assert.Equal(t, []string{"linux", "darwin"}, values["TARGETS"], "TARGETS in %s", name)
+		}
+	}
+}
+
+func TestMergeBuildParameters(t *testing.T) {
+	base := url.Values{"VERSION": []string{"1.0.0"}, "TARGETS": []string{"linux", "darwin"}}
+	overrides := url.Values{"VERSION": []string{"2.0.0"}}
+
+	values := jenkinsutil.MergeBuildParameters(base, overrides)
+	assert.Equal(t, []string{"2.0.0"}, values["VERSION"], "VERSION")
+	assert.Equal(t, []string{"linux", "darwin"}, values["TARGETS"], "TARGETS")
+}
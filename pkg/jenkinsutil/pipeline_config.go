@@ -0,0 +1,104 @@
+package jenkinsutil
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PipelineConfig the settings of a standalone pipeline job which trigger-pipeline manages. The git and
+// Jenkinsfile settings are always compared whereas the other settings are only compared if the desired
+// pipeline specifies them, such as a job template which adds a description or build discarder
+type PipelineConfig struct {
+	GitURL        string
+	CredentialsID string
+	Branch        string
+	ScriptPath    string
+	Description   string
+	Parameters    []string
+	NumToKeep     string
+	Lightweight   string
+}
+
+type pipelineConfigXML struct {
+	XMLName        xml.Name                 `xml:"flow-definition"`
+	URLs           []string                 `xml:"definition>scm>userRemoteConfigs>hudson.plugins.git.UserRemoteConfig>url"`
+	CredentialsIDs []string                 `xml:"definition>scm>userRemoteConfigs>hudson.plugins.git.UserRemoteConfig>credentialsId"`
+	Branches       []string                 `xml:"definition>scm>branches>hudson.plugins.git.BranchSpec>name"`
+	ScriptPath     string                   `xml:"definition>scriptPath"`
+	Lightweight    string                   `xml:"definition>lightweight"`
+	Description    string                   `xml:"description"`
+	Parameters     *parameterDefinitionsXML `xml:"properties>hudson.model.ParametersDefinitionProperty>parameterDefinitions"`
+	NumToKeep      string                   `xml:"properties>jenkins.model.BuildDiscarderProperty>strategy>numToKeep"`
+}
+
+// parameterDefinitionsXML the build parameters of a job which can be of any parameter definition class
+type parameterDefinitionsXML struct {
+	Definitions []struct {
+		Name string `xml:"name"`
+	} `xml:",any"`
+}
+
+// ParsePipelineConfig parses the settings of a standalone pipeline job from its config.xml
+func ParsePipelineConfig(configXML string) (*PipelineConfig, error) {
+	c := &pipelineConfigXML{}
+	err := xml.Unmarshal([]byte(stripXMLDeclaration(configXML)), c)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse pipeline config.xml")
+	}
+	config := &PipelineConfig{
+		GitURL:        strings.Join(c.URLs, ","),
+		CredentialsID: strings.Join(c.CredentialsIDs, ","),
+		Branch:        strings.Join(c.Branches, ","),
+		ScriptPath:    c.ScriptPath,
+		Description:   strings.TrimSpace(c.Description),
+		NumToKeep:     strings.TrimSpace(c.NumToKeep),
+		Lightweight:   strings.TrimSpace(c.Lightweight),
+	}
+	if c.Parameters != nil {
+		for _, d := range c.Parameters.Definitions {
+			config.Parameters = append(config.Parameters, d.Name)
+		}
+	}
+	return config, nil
+}
+
+// stripXMLDeclaration removes the XML declaration as Jenkins uses XML 1.1 which the go XML decoder rejects
+func stripXMLDeclaration(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "<?xml") {
+		i := strings.Index(text, "?>")
+		if i > 0 {
+			text = text[i+2:]
+		}
+	}
+	return text
+}
+
+// Diff returns a description of each setting which differs from the desired configuration
+func (c *PipelineConfig) Diff(desired *PipelineConfig) []string {
+	var answer []string
+	diff := func(name, actual, expected string) {
+		if actual != expected {
+			answer = append(answer, fmt.Sprintf("%s is '%s' but should be '%s'", name, actual, expected))
+		}
+	}
+	diff("git URL", c.GitURL, desired.GitURL)
+	diff("git credentials", c.CredentialsID, desired.CredentialsID)
+	diff("branch", c.Branch, desired.Branch)
+	diff("Jenkinsfile", c.ScriptPath, desired.ScriptPath)
+
+	// the other settings are only compared if the desired pipeline specifies them
+	optionalDiff := func(name, actual, expected string) {
+		if expected != "" {
+			diff(name, actual, expected)
+		}
+	}
+	optionalDiff("description", c.Description, desired.Description)
+	optionalDiff("build parameters", strings.Join(c.Parameters, ","), strings.Join(desired.Parameters, ","))
+	optionalDiff("builds to keep", c.NumToKeep, desired.NumToKeep)
+	optionalDiff("lightweight checkout", c.Lightweight, desired.Lightweight)
+	return answer
+}
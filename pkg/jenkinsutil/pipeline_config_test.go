@@ -0,0 +1,94 @@
+package jenkinsutil_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x-labs/trigger-pipeline/pkg/jenkinsutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePipelineConfig(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "pipeline", "config.xml"))
+	require.NoError(t, err, "failed to load config.xml")
+
+	config, err := jenkinsutil.ParsePipelineConfig(string(data))
+	require.NoError(t, err, "failed to parse config.xml")
+
+	expected := &jenkinsutil.PipelineConfig{
+		GitURL:      "https://github.com/myowner/myrepo.git",
+		Branch:      "*/master",
+		ScriptPath:  "Jenkinsfile",
+		Lightweight: "true",
+	}
+	assert.Equal(t, expected, config, "pipeline config")
+	assert.Empty(t, config.Diff(expected), "should have no differences")
+
+	desired := &jenkinsutil.PipelineConfig{
+		GitURL:     "https://github.com/myowner/myrepo.git",
+		Branch:     "*/develop",
+		ScriptPath: "Jenkinsfile",
+	}
+	assert.Equal(t, []string{"branch is '*/master' but should be '*/develop'"}, config.Diff(desired), "differences")
+}
+
+func TestPipelineConfigDiffTemplateSettings(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("test_data", "pipeline", "config.xml"))
+	require.NoError(t, err, "failed to load config.xml")
+	current, err := jenkinsutil.ParsePipelineConfig(string(data))
+	require.NoError(t, err, "failed to parse config.xml")
+
+	desiredXML := `<?xml version='1.1' encoding='UTF-8'?>
+<flow-definition plugin="workflow-job">
+  <description>myowner/myrepo master</description>
+  <properties>
+    <jenkins.model.BuildDiscarderProperty>
+      <strategy class="hudson.tasks.LogRotator">
+        <numToKeep>20</numToKeep>
+      </strategy>
+    </jenkins.model.BuildDiscarderProperty>
+    <hudson.model.ParametersDefinitionProperty>
+      <parameterDefinitions>
+        <hudson.model.StringParameterDefinition>
+          <name>VERSION</name>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.BooleanParameterDefinition>
+          <name>RELEASE</name>
+        </hudson.model.BooleanParameterDefinition>
+      </parameterDefinitions>
+    </hudson.model.ParametersDefinitionProperty>
+  </properties>
+  <definition class="org.jenkinsci.plugins.workflow.cps.CpsScmFlowDefinition" plugin="workflow-cps">
+    <scm class="hudson.plugins.git.GitSCM" plugin="git">
+      <userRemoteConfigs>
+        <hudson.plugins.git.UserRemoteConfig>
+          <url>https://github.com/myowner/myrepo.git</url>
+        </hudson.plugins.git.UserRemoteConfig>
+      </userRemoteConfigs>
+      <branches>
+        <hudson.plugins.git.BranchSpec>
+          <name>*/master</name>
+        </hudson.plugins.git.BranchSpec>
+      </branches>
+    </scm>
+    <scriptPath>Jenkinsfile</scriptPath>
+  </definition>
+</flow-definition>`
+	desired, err := jenkinsutil.ParsePipelineConfig(desiredXML)
+	require.NoError(t, err, "failed to parse desired pipeline XML")
+	assert.Equal(t, []string{"VERSION", "RELEASE"}, desired.Parameters, "parameters")
+
+	expected := []string{
+		"description is '' but should be 'myowner/myrepo master'",
+		"build parameters is '' but should be 'VERSION,RELEASE'",
+		"builds to keep is '' but should be '20'",
+	}
+	assert.Equal(t, expected, current.Diff(desired), "differences")
+	assert.Empty(t, desired.Diff(desired), "should have no differences")
+
+	// settings the desired pipeline does not specify are not compared
+	core := &jenkinsutil.PipelineConfig{GitURL: desired.GitURL, Branch: desired.Branch, ScriptPath: desired.ScriptPath}
+	assert.Empty(t, desired.Diff(core), "should only compare the settings of the desired pipeline")
+}
@@ -2,9 +2,11 @@ package jenkinsutil
 
 import (
 	"net/http"
+	"net/http/cookiejar"
 	"strings"
 
 	gojenkins "github.com/jenkins-x/golang-jenkins"
+	"github.com/pkg/errors"
 )
 
 // JenkinsServer represents a jenkins server discovered via Service selectors or via the
@@ -24,15 +26,21 @@ type JenkinsServer struct {
 }
 
 // CreateClient creates a Jenkins client for a jenkins service
-func (j *JenkinsServer) CreateClient() (gojenkins.JenkinsClient, error) {
+func (j *JenkinsServer) CreateClient() (Client, error) {
 	// lets trim trailing slashes to avoid the client using a // in the generated URLs
 	u := strings.TrimSuffix(j.URL, "/")
-	jenkins := gojenkins.NewJenkins(&j.Auth, u)
+
+	// lets keep the session cookie as Jenkins ties the CSRF crumb to the session
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the cookie jar")
+	}
 	httpClient := &http.Client{
+		Jar:       jar,
+		Transport: newCrumbTransport(u, http.DefaultTransport, jar),
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
 	}
-	jenkins.SetHTTPClient(httpClient)
-	return jenkins, nil
+	return NewClient(&j.Auth, u, httpClient), nil
 }